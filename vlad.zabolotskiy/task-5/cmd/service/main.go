@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/handlers"
+	"github.com/se1lzor/task-5/pkg/pipeline"
+	"github.com/se1lzor/task-5/pkg/service"
+)
+
+const (
+	defaultSize     = 16
+	shutdownTimeout = 10 * time.Second
+	readTimeout     = 10 * time.Second
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	specPath := flag.String("spec", "", "pipeline spec in YAML or JSON")
+	flag.Parse()
+
+	if err := run(*addr, *specPath); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func build(specPath string) (*conveyer.StringConveyer, error) {
+	if specPath == "" {
+		fallback := conveyer.New(defaultSize)
+		fallback.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		return fallback, nil
+	}
+
+	spec, err := pipeline.Load(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading spec: %w", err)
+	}
+
+	built, err := pipeline.BuildStrings(spec, pipeline.DefaultRegistry())
+	if err != nil {
+		return nil, fmt.Errorf("building pipeline: %w", err)
+	}
+
+	return built, nil
+}
+
+func run(addr string, specPath string) error {
+	pipe, err := build(specPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runDone := make(chan error, 1)
+
+	go func() {
+		runDone <- pipe.Run(context.WithoutCancel(ctx))
+	}()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           service.NewServer(pipe),
+		ReadHeaderTimeout: readTimeout,
+	}
+
+	serveDone := make(chan error, 1)
+
+	go func() {
+		log.Printf("conveyer service listening on %s", addr)
+		serveDone <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveDone:
+		return fmt.Errorf("serving: %w", err)
+	case err := <-runDone:
+		return fmt.Errorf("conveyer stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	report, shutdownErr := pipe.Shutdown(shutdownCtx)
+	if report.LostCount() > 0 {
+		log.Printf("conveyer shut down with %d lost messages", report.LostCount())
+	}
+
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Join(shutdownErr, fmt.Errorf("stopping server: %w", err))
+	}
+
+	return shutdownErr
+}
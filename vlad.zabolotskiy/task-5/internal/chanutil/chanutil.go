@@ -0,0 +1,37 @@
+package chanutil
+
+import "reflect"
+
+func Make[T any](count int) []chan T {
+	channels := make([]chan T, count)
+
+	for index := range channels {
+		channels[index] = make(chan T)
+	}
+
+	return channels
+}
+
+func CloseAll[T any](channels []chan T) {
+	for _, channel := range channels {
+		close(channel)
+	}
+}
+
+func RecvCase(channel any) reflect.SelectCase {
+	return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(channel), Send: reflect.Value{}}
+}
+
+func SendCase[T any](channel chan T, data T) reflect.SelectCase {
+	return reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(channel), Send: reflect.ValueOf(&data).Elem()}
+}
+
+func RecvCases[T any](channels []chan T) []reflect.SelectCase {
+	cases := make([]reflect.SelectCase, len(channels))
+
+	for index, channel := range channels {
+		cases[index] = RecvCase(channel)
+	}
+
+	return cases
+}
@@ -0,0 +1,180 @@
+package conveyer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type Pressure struct {
+	Length       int
+	Size         int
+	BlockedSends uint64
+	BlockedTime  time.Duration
+}
+
+type outputRoomKey struct{}
+
+type outputRoomFunc func(index int) (int, bool)
+
+func OutputRoom(ctx context.Context, index int) (int, bool) {
+	room, ok := ctx.Value(outputRoomKey{}).(outputRoomFunc)
+	if !ok {
+		return 0, false
+	}
+
+	return room(index)
+}
+
+func withOutputRoom[T any](ctx context.Context, outputs []chan T, inFlight []atomic.Int64) context.Context {
+	return context.WithValue(ctx, outputRoomKey{}, outputRoomFunc(func(index int) (int, bool) {
+		if index < 0 || index >= len(outputs) {
+			return 0, false
+		}
+
+		return cap(outputs[index]) - len(outputs[index]) - int(inFlight[index].Load()), true
+	}))
+}
+
+func WithChannelSize(channel string, size int) HandlerOption {
+	return func(options *handlerOptions) {
+		options.channelSizes[channel] = size
+	}
+}
+
+func (c *Conveyer[T]) sendBlocking(ctx context.Context, name string, channel chan T, data T) error {
+	select {
+	case channel <- data:
+		return nil
+	default:
+	}
+
+	start := time.Now()
+
+	defer func() {
+		c.recordBlocked(name, time.Since(start))
+	}()
+
+	select {
+	case channel <- data:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+}
+
+func (c *Conveyer[T]) readSignal(name string) <-chan struct{} {
+	c.readMutex.Lock()
+	defer c.readMutex.Unlock()
+
+	signal, exists := c.readSignals[name]
+	if !exists {
+		signal = make(chan struct{})
+		c.readSignals[name] = signal
+	}
+
+	return signal
+}
+
+func (c *Conveyer[T]) signalRead(name string) {
+	c.readMutex.Lock()
+	defer c.readMutex.Unlock()
+
+	if signal, exists := c.readSignals[name]; exists {
+		close(signal)
+		delete(c.readSignals, name)
+	}
+}
+
+func (c *Conveyer[T]) waitForRoom(ctx context.Context, name string, channel chan T) {
+	if cap(channel) == 0 {
+		return
+	}
+
+	var start time.Time
+
+	for {
+		signal := c.readSignal(name)
+
+		if len(channel) < cap(channel) {
+			break
+		}
+
+		if start.IsZero() {
+			start = time.Now()
+		}
+
+		select {
+		case <-signal:
+		case <-ctx.Done():
+			c.recordBlocked(name, time.Since(start))
+
+			return
+		}
+	}
+
+	if !start.IsZero() {
+		c.recordBlocked(name, time.Since(start))
+	}
+}
+
+func (c *Conveyer[T]) waitDrained(ctx context.Context, names []string, channels []chan T) error {
+	for index, channel := range channels {
+		for {
+			signal := c.readSignal(names[index])
+
+			if len(channel) == 0 {
+				break
+			}
+
+			select {
+			case <-signal:
+			case <-ctx.Done():
+				return ctx.Err() //nolint:wrapcheck
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Conveyer[T]) recordBlocked(name string, blocked time.Duration) {
+	c.mutex.Lock()
+
+	stats, exists := c.pressure[name]
+	if !exists {
+		stats = &Pressure{Length: 0, Size: 0, BlockedSends: 0, BlockedTime: 0}
+		c.pressure[name] = stats
+	}
+
+	stats.BlockedSends++
+	stats.BlockedTime += blocked
+	sink := c.metrics
+	c.mutex.Unlock()
+
+	if blockedSink, ok := sink.(BlockedSink); ok {
+		blockedSink.ChannelBlocked(name, blocked)
+	}
+}
+
+func (c *Conveyer[T]) Pressure(name string) (Pressure, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	channel, exists := c.channels[name]
+	if !exists {
+		var zero Pressure
+
+		return zero, fmt.Errorf("%w", ErrChanNotFound)
+	}
+
+	pressure := Pressure{Length: len(channel), Size: cap(channel), BlockedSends: 0, BlockedTime: 0}
+
+	if stats, exists := c.pressure[name]; exists {
+		pressure.BlockedSends = stats.BlockedSends
+		pressure.BlockedTime = stats.BlockedTime
+	}
+
+	return pressure, nil
+}
@@ -0,0 +1,71 @@
+package conveyer_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Backpressure(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out", conveyer.WithChannelSize("out", 3))
+
+	pressure, err := pipeline.Pressure("out")
+	require.NoError(t, err)
+	require.Equal(t, 3, pressure.Size)
+
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, pipeline.SendContext(ctx, "in", "b"), context.DeadlineExceeded)
+
+	pressure, err = pipeline.Pressure("in")
+	require.NoError(t, err)
+	require.Equal(t, conveyer.Pressure{Length: 1, Size: 1, BlockedSends: 1, BlockedTime: pressure.BlockedTime}, pressure)
+	require.Positive(t, pressure.BlockedTime)
+
+	_, err = pipeline.Pressure("missing")
+	require.Error(t, err)
+}
+
+type countingSink struct {
+	in atomic.Int64
+}
+
+func (s *countingSink) ChannelIn(string)                     { s.in.Add(1) }
+func (s *countingSink) ChannelOut(string)                    {}
+func (s *countingSink) ChannelOccupancy(string, int, int)    {}
+func (s *countingSink) HandlerLatency(string, time.Duration) {}
+func (s *countingSink) HandlerError(string, error)           {}
+
+func TestConveyer_BackpressureSinks(t *testing.T) {
+	t.Parallel()
+
+	basic := &countingSink{in: atomic.Int64{}}
+	memory := conveyer.NewInMemoryMetrics()
+
+	for _, sink := range []conveyer.MetricsSink{basic, memory} {
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+		pipeline.SetMetrics(sink)
+
+		require.NoError(t, pipeline.Send("in", "a"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		require.ErrorIs(t, pipeline.SendContext(ctx, "in", "b"), context.DeadlineExceeded)
+		cancel()
+	}
+
+	require.Positive(t, basic.in.Load())
+	require.Positive(t, memory.Snapshot().Channels["in"].Blocked)
+}
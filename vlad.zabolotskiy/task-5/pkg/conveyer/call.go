@@ -0,0 +1,297 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrNoCorrelator     = errors.New("correlator is not configured")
+	ErrUncorrelated     = errors.New("reply has no waiting caller")
+	ErrReservedForCalls = errors.New("output is reserved for call replies")
+	ErrCallsPending     = errors.New("output has calls waiting for replies")
+)
+
+type Correlator[T any] interface {
+	Tag(data T, id string) T
+	Correlate(data T) (string, bool)
+}
+
+type callReply[T any] struct {
+	data T
+	err  error
+}
+
+type replyRouter[T any] struct {
+	mutex   sync.Mutex
+	waiters map[string]chan callReply[T]
+	ctx     context.Context //nolint:containedctx
+	cancel  context.CancelFunc
+}
+
+func (c *Conveyer[T]) SetCorrelator(correlator Correlator[T]) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.correlator = correlator
+}
+
+func (c *Conveyer[T]) Call(ctx context.Context, input string, output string, data T) (T, error) {
+	var zero T
+
+	c.mutex.RLock()
+	correlator := c.correlator
+	c.mutex.RUnlock()
+
+	if correlator == nil {
+		return zero, ErrNoCorrelator
+	}
+
+	if _, err := c.lookupOutput(output); err != nil {
+		return zero, fmt.Errorf("calling %q: %w", output, err)
+	}
+
+	id := strconv.FormatUint(c.nextCallID.Add(1), 10)
+	router, reply := c.awaitReply(output, id)
+
+	if err := c.SendContext(ctx, input, correlator.Tag(data, id)); err != nil {
+		router.forget(id)
+
+		return zero, fmt.Errorf("calling %q: %w", input, err)
+	}
+
+	select {
+	case result := <-reply:
+		if result.err != nil {
+			return zero, fmt.Errorf("calling %q: %w", output, result.err)
+		}
+
+		return result.data, nil
+	case <-ctx.Done():
+		router.forget(id)
+
+		return zero, fmt.Errorf("calling %q: %w", output, ctx.Err())
+	}
+}
+
+func (c *Conveyer[T]) awaitReply(output string, id string) (*replyRouter[T], chan callReply[T]) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	router, exists := c.routers[output]
+	if !exists {
+		router = &replyRouter[T]{
+			mutex:   sync.Mutex{},
+			waiters: make(map[string]chan callReply[T]),
+			ctx:     nil,
+			cancel:  nil,
+		}
+		c.routers[output] = router
+	}
+
+	return router, router.wait(c, output, id, c.groupCtx)
+}
+
+func (c *Conveyer[T]) startRouters(ctx context.Context) {
+	for output, router := range c.routers {
+		router.mutex.Lock()
+
+		if len(router.waiters) > 0 {
+			router.start(c, output, ctx)
+		}
+
+		router.mutex.Unlock()
+	}
+}
+
+func (c *Conveyer[T]) ReleaseOutput(output string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	router, exists := c.routers[output]
+	if !exists {
+		return nil
+	}
+
+	router.mutex.Lock()
+	defer router.mutex.Unlock()
+
+	if len(router.waiters) > 0 {
+		return fmt.Errorf("releasing %q: %w", output, ErrCallsPending)
+	}
+
+	router.stop()
+	delete(c.routers, output)
+
+	return nil
+}
+
+func (c *Conveyer[T]) lookupReadable(output string) (chan T, error) {
+	c.mutex.RLock()
+	_, reserved := c.routers[output]
+	c.mutex.RUnlock()
+
+	if reserved {
+		return nil, fmt.Errorf("receiving from %q: %w", output, ErrReservedForCalls)
+	}
+
+	return c.lookupOutput(output)
+}
+
+func (c *Conveyer[T]) recvReply(ctx context.Context, output string) (T, error) {
+	var zero T
+
+	channel, err := c.lookupOutput(output)
+	if err != nil {
+		return zero, err
+	}
+
+	select {
+	case data, ok := <-channel:
+		return c.received(output, channel, data, ok)
+	case <-ctx.Done():
+	}
+
+	select {
+	case data, ok := <-channel:
+		return c.received(output, channel, data, ok)
+	default:
+		return zero, fmt.Errorf("receiving from %q: %w", output, ctx.Err())
+	}
+}
+
+func (r *replyRouter[T]) wait(
+	conveyer *Conveyer[T],
+	output string,
+	id string,
+	runCtx context.Context, //nolint:revive
+) chan callReply[T] {
+	reply := make(chan callReply[T], 1)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.waiters[id] = reply
+
+	if runCtx != nil {
+		r.start(conveyer, output, runCtx)
+	}
+
+	return reply
+}
+
+func (r *replyRouter[T]) start(conveyer *Conveyer[T], output string, runCtx context.Context) {
+	if r.ctx != nil && r.ctx.Err() == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(runCtx)
+	r.ctx, r.cancel = ctx, cancel
+
+	go r.route(ctx, conveyer, output)
+}
+
+func (r *replyRouter[T]) stop() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	r.ctx, r.cancel = nil, nil
+}
+
+func (r *replyRouter[T]) forget(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.waiters, id)
+	r.stopIfIdle()
+}
+
+func (r *replyRouter[T]) stopIfIdle() {
+	if len(r.waiters) > 0 {
+		return
+	}
+
+	r.stop()
+}
+
+func (r *replyRouter[T]) route(ctx context.Context, conveyer *Conveyer[T], output string) {
+	rejectCtx := conveyer.withRejecter(ctx, "call["+output+"]")
+
+	for {
+		data, err := conveyer.recvReply(ctx, output)
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			r.stopped(ctx)
+
+			return
+		}
+
+		if err != nil {
+			r.failAll(err)
+
+			return
+		}
+
+		if !r.deliver(conveyer, data) {
+			Reject(rejectCtx, data, ErrUncorrelated.Error())
+		}
+	}
+}
+
+func (r *replyRouter[T]) deliver(conveyer *Conveyer[T], data T) bool {
+	conveyer.mutex.RLock()
+	correlator := conveyer.correlator
+	conveyer.mutex.RUnlock()
+
+	id, ok := correlator.Correlate(data)
+	if !ok {
+		return false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	reply, exists := r.waiters[id]
+	if !exists {
+		return false
+	}
+
+	delete(r.waiters, id)
+	reply <- callReply[T]{data: data, err: nil}
+	r.stopIfIdle()
+
+	return true
+}
+
+func (r *replyRouter[T]) stopped(ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.ctx != ctx {
+		return
+	}
+
+	r.failLocked(ErrNotRunning)
+}
+
+func (r *replyRouter[T]) failAll(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.failLocked(err)
+}
+
+func (r *replyRouter[T]) failLocked(err error) {
+	var zero T
+
+	for id, reply := range r.waiters {
+		delete(r.waiters, id)
+		reply <- callReply[T]{data: zero, err: err}
+	}
+
+	r.stop()
+}
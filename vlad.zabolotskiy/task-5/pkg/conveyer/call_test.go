@@ -0,0 +1,235 @@
+package conveyer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	ID    string
+	Value int
+}
+
+type requestCorrelator struct{}
+
+func (requestCorrelator) Tag(data request, id string) request {
+	data.ID = id
+
+	return data
+}
+
+func (requestCorrelator) Correlate(data request) (string, bool) {
+	return data.ID, data.ID != ""
+}
+
+func squareDecorator(ctx context.Context, input chan request, output chan request) error {
+	defer close(output)
+
+	for data := range input {
+		if data.Value < 0 {
+			conveyer.Reject(ctx, data, "negative value")
+
+			continue
+		}
+
+		data.Value *= data.Value
+
+		select {
+		case output <- data:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func runCalls(t *testing.T) (*conveyer.Conveyer[request], func()) {
+	t.Helper()
+
+	pipeline := conveyer.NewTyped[request](8)
+	pipeline.SetCorrelator(requestCorrelator{})
+	pipeline.RegisterDecorator(conveyer.Parallel(squareDecorator, 4, conveyer.Unordered), "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	return pipeline, func() {
+		require.NoError(t, harness.Stop())
+	}
+}
+
+func TestConveyer_CallConcurrent(t *testing.T) {
+	t.Parallel()
+
+	pipeline, stop := runCalls(t)
+	defer stop()
+
+	var group sync.WaitGroup
+
+	errs := make(chan error, 50)
+
+	for value := range 50 {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			reply, err := pipeline.Call(context.Background(), "in", "out", request{ID: "", Value: value})
+			if err == nil && reply.Value != value*value {
+				err = errors.New("mismatched reply")
+			}
+
+			errs <- err
+		}()
+	}
+
+	group.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestConveyer_CallTimeout(t *testing.T) {
+	t.Parallel()
+
+	pipeline, stop := runCalls(t)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := pipeline.Call(ctx, "in", "out", request{ID: "", Value: -1})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	reply, err := pipeline.Call(context.Background(), "in", "out", request{ID: "", Value: 3})
+	require.NoError(t, err)
+	require.Equal(t, 9, reply.Value)
+}
+
+func TestConveyer_CallClosedOutput(t *testing.T) {
+	t.Parallel()
+
+	pipeline, stop := runCalls(t)
+	defer stop()
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := pipeline.Call(context.Background(), "in", "out", request{ID: "", Value: -1})
+		result <- err
+	}()
+
+	for event := range events {
+		if event.Kind == conveyer.EventMessageDropped {
+			break
+		}
+	}
+
+	_, err := pipeline.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.ErrorIs(t, <-result, conveyer.ErrClosed)
+}
+
+func TestConveyer_CallReservesOutput(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.NewTyped[request](4)
+	pipeline.SetCorrelator(requestCorrelator{})
+	require.NoError(t, pipeline.SetDeadLetter("dead"))
+	pipeline.RegisterDecorator(squareDecorator, "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", request{ID: "", Value: 2}))
+
+	reply, err := pipeline.Call(context.Background(), "in", "out", request{ID: "", Value: 3})
+	require.NoError(t, err)
+	require.Equal(t, 9, reply.Value)
+
+	letter, err := pipeline.RecvDeadLetter(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, conveyer.DeadLetter[request]{
+		Data:    request{ID: "", Value: 4},
+		Reason:  conveyer.ErrUncorrelated.Error(),
+		Handler: "call[out]",
+	}, letter)
+
+	_, err = pipeline.Recv("out")
+	require.ErrorIs(t, err, conveyer.ErrReservedForCalls)
+
+	require.NoError(t, pipeline.ReleaseOutput("out"))
+	require.NoError(t, pipeline.Send("in", request{ID: "", Value: 5}))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, request{ID: "", Value: 25}, data)
+
+	_, err = pipeline.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, harness.Wait())
+}
+
+func TestConveyer_CallErrors(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.NewTyped[request](1)
+	pipeline.RegisterDecorator(squareDecorator, "in", "out")
+
+	_, err := pipeline.Call(context.Background(), "in", "out", request{ID: "", Value: 1})
+	require.ErrorIs(t, err, conveyer.ErrNoCorrelator)
+
+	pipeline.SetCorrelator(requestCorrelator{})
+
+	_, err = pipeline.Call(context.Background(), "in", "missing", request{ID: "", Value: 1})
+	require.ErrorIs(t, err, conveyer.ErrChanNotFound)
+}
+
+func TestConveyer_CallStopsWithRun(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan request)
+	pipeline := conveyer.NewTyped[request](1)
+	pipeline.SetCorrelator(requestCorrelator{})
+	pipeline.RegisterDecorator(func(ctx context.Context, input chan request, _ chan request) error {
+		for {
+			select {
+			case data := <-input:
+				received <- data
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}, "in", "out")
+
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := pipeline.Call(context.Background(), "in", "out", request{ID: "", Value: 1})
+		result <- err
+	}()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	<-received
+	require.ErrorIs(t, pipeline.ReleaseOutput("out"), conveyer.ErrCallsPending)
+
+	require.NoError(t, harness.Stop())
+	require.ErrorIs(t, <-result, conveyer.ErrNotRunning)
+	require.NoError(t, pipeline.ReleaseOutput("out"))
+}
@@ -4,98 +4,372 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
 var (
 	errAlreadyRunning = errors.New("already running")
-	errChanNotFound   = errors.New("chan not found")
+
+	ErrChanNotFound    = errors.New("chan not found")
+	ErrChanFull        = errors.New("channel is full")
+	ErrNoDataAvailable = errors.New("no data available")
+	ErrClosed          = fmt.Errorf("channel is closed: %w", io.EOF)
+)
+
+type (
+	DecoratorFunc[T any]   func(ctx context.Context, input chan T, output chan T) error
+	MultiplexerFunc[T any] func(ctx context.Context, inputs []chan T, output chan T) error
+	SeparatorFunc[T any]   func(ctx context.Context, input chan T, outputs []chan T) error
+)
+
+type HandlerKind string
+
+const (
+	KindDecorator   HandlerKind = "decorator"
+	KindMultiplexer HandlerKind = "multiplexer"
+	KindSeparator   HandlerKind = "separator"
 )
 
-type Conveyer struct {
-	size       int
-	channels   map[string]chan string
-	handlers   []func(ctx context.Context) error
-	mutex      sync.RWMutex
-	isRunning  bool
-	cancelFunc context.CancelFunc
+type handlerSpec struct {
+	name    string
+	kind    HandlerKind
+	inputs  []string
+	outputs []string
 }
 
-func New(size int) *Conveyer {
-	return &Conveyer{
-		size:       size,
-		channels:   make(map[string]chan string),
-		handlers:   []func(ctx context.Context) error{},
-		mutex:      sync.RWMutex{},
-		isRunning:  false,
-		cancelFunc: nil,
+type handler[T any] struct {
+	handlerSpec
+	options handlerOptions
+	run     func(ctx context.Context, inputs []chan T, outputs []chan T) error
+}
+
+type Conveyer[T any] struct {
+	size            int
+	channels        map[string]chan T
+	handlers        []handler[T]
+	declaredInputs  []string
+	declaredOutputs []string
+	metrics         MetricsSink
+	isolated        []error
+	pressure        map[string]*Pressure
+	lost            map[string][]T
+	durable         map[string]*durableChannel[T]
+	deadLetterName  string
+	deadLetters     chan DeadLetter[T]
+	nextHandlerID   int
+	mutex           sync.RWMutex
+	isRunning       bool
+	isShutdown      bool
+	cancelFunc      context.CancelFunc
+	done            chan struct{}
+	group           *errgroup.Group
+	groupCtx        context.Context //nolint:containedctx
+	liveHandlers    int
+	running         map[string]*runningHandler
+	sending         sync.WaitGroup
+	states          map[string]*HandlerStatus
+	eventMutex      sync.RWMutex
+	hooks           []Hook
+	subscribers     map[int]*subscription
+	nextSubscriber  int
+	handlerStates   map[string]*State
+	checkpointPath  string
+	checkpointEvery time.Duration
+	correlator      Correlator[T]
+	routers         map[string]*replyRouter[T]
+	nextCallID      atomic.Uint64
+	abort           chan struct{}
+	readMutex       sync.Mutex
+	readSignals     map[string]chan struct{}
+	closedChannels  map[string]bool
+}
+
+type StringConveyer = Conveyer[string]
+
+func New(size int) *StringConveyer {
+	return NewTyped[string](size)
+}
+
+func NewTyped[T any](size int) *Conveyer[T] {
+	return &Conveyer[T]{
+		size:            size,
+		channels:        make(map[string]chan T),
+		handlers:        []handler[T]{},
+		declaredInputs:  nil,
+		declaredOutputs: nil,
+		metrics:         nil,
+		isolated:        nil,
+		pressure:        make(map[string]*Pressure),
+		lost:            make(map[string][]T),
+		durable:         make(map[string]*durableChannel[T]),
+		deadLetterName:  "",
+		deadLetters:     nil,
+		nextHandlerID:   0,
+		mutex:           sync.RWMutex{},
+		isRunning:       false,
+		isShutdown:      false,
+		cancelFunc:      nil,
+		done:            nil,
+		group:           nil,
+		groupCtx:        nil,
+		liveHandlers:    0,
+		running:         make(map[string]*runningHandler),
+		sending:         sync.WaitGroup{},
+		states:          make(map[string]*HandlerStatus),
+		eventMutex:      sync.RWMutex{},
+		hooks:           nil,
+		subscribers:     make(map[int]*subscription),
+		nextSubscriber:  0,
+		handlerStates:   make(map[string]*State),
+		checkpointPath:  "",
+		checkpointEvery: 0,
+		correlator:      nil,
+		routers:         make(map[string]*replyRouter[T]),
+		nextCallID:      atomic.Uint64{},
+		abort:           nil,
+		readMutex:       sync.Mutex{},
+		readSignals:     make(map[string]chan struct{}),
+		closedChannels:  make(map[string]bool),
 	}
 }
 
-func (c *Conveyer) getChannel(name string) chan string {
+func (c *Conveyer[T]) getChannel(name string) chan T {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if channel, exists := c.channels[name]; exists {
+	return c.ensureChannel(name)
+}
+
+func (c *Conveyer[T]) ensureChannel(name string) chan T {
+	return c.ensureSizedChannel(name, c.size)
+}
+
+func (c *Conveyer[T]) ensureSizedChannel(name string, size int) chan T {
+	channel, exists := c.channels[name]
+	if exists && (cap(channel) == size || len(channel) > size || c.isRunning) {
 		return channel
 	}
 
-	channel := make(chan string, c.size)
-	c.channels[name] = channel
+	resized := make(chan T, size)
+
+	if exists {
+		for len(channel) > 0 {
+			resized <- <-channel
+		}
+	}
+
+	c.channels[name] = resized
+
+	return resized
+}
+
+func (c *Conveyer[T]) register(
+	kind HandlerKind,
+	inputs []string,
+	outputs []string,
+	options []HandlerOption,
+	run func(ctx context.Context, inputs []chan T, outputs []chan T) error,
+) error {
+	return c.install("", []stageHandler[T]{{
+		kind:    kind,
+		inputs:  inputs,
+		outputs: outputs,
+		options: options,
+		run:     run,
+	}}, nil, nil)
+}
+
+func (c *Conveyer[T]) install(prefix string, staged []stageHandler[T], inputs []string, outputs []string) error {
+	c.mutex.Lock()
+
+	if prefix != "" {
+		if err := checkPrefix(prefix, slices.Collect(maps.Keys(c.channels))); err != nil {
+			c.mutex.Unlock()
+
+			return err
+		}
+	}
+
+	added := make([]handler[T], len(staged))
+	for index, registered := range staged {
+		added[index] = handler[T]{
+			handlerSpec: handlerSpec{
+				name:    fmt.Sprintf("%s[%d]", registered.kind, c.nextHandlerID+index),
+				kind:    registered.kind,
+				inputs:  append([]string(nil), registered.inputs...),
+				outputs: append([]string(nil), registered.outputs...),
+			},
+			options: newHandlerOptions(registered.options),
+			run:     registered.run,
+		}
+	}
+
+	hot := c.isRunning && c.liveHandlers > 0
+
+	if hot {
+		if err := c.validateWith(added, inputs, outputs); err != nil {
+			c.mutex.Unlock()
+			c.rejectAdded(added, err)
+
+			return fmt.Errorf("adding %s: %w", handlerNames(added), err)
+		}
+	}
+
+	c.declaredInputs = append(c.declaredInputs, inputs...)
+	c.declaredOutputs = append(c.declaredOutputs, outputs...)
+
+	for _, registered := range added {
+		c.ensureHandlerChannels(registered)
+	}
+
+	c.nextHandlerID += len(added)
+	c.handlers = append(c.handlers, added...)
+
+	if hot {
+		for _, registered := range added {
+			c.startHandler(registered)
+		}
+	}
+
+	c.mutex.Unlock()
+
+	return nil
+}
+
+func (c *Conveyer[T]) validateWith(added []handler[T], inputs []string, outputs []string) error {
+	specs := c.specs()
+	for _, registered := range added {
+		specs = append(specs, registered.handlerSpec)
+	}
+
+	declaredInputs := append(slices.Clone(c.declaredInputs), inputs...)
+	declaredOutputs := append(slices.Clone(c.declaredOutputs), outputs...)
 
-	return channel
+	return newTopology(specs, declaredInputs, declaredOutputs).validate()
 }
 
-func (c *Conveyer) RegisterDecorator(
-	decoratorFunction func(context.Context, chan string, chan string) error,
+func (c *Conveyer[T]) rejectAdded(added []handler[T], err error) {
+	for _, registered := range added {
+		c.emit(newEvent(EventHandlerRejected, registered.name, "", 0, fmt.Errorf("adding %s: %w", registered.name, err)))
+	}
+}
+
+func (c *Conveyer[T]) ensureHandlerChannels(registered handler[T]) {
+	for _, name := range registered.outputs {
+		if c.closedChannels[name] {
+			c.channels[name] = make(chan T, cap(c.channels[name]))
+			delete(c.closedChannels, name)
+		}
+	}
+
+	for _, name := range append(append([]string(nil), registered.inputs...), registered.outputs...) {
+		if size, exists := registered.options.channelSizes[name]; exists {
+			c.ensureSizedChannel(name, size)
+		} else if _, exists := c.channels[name]; !exists {
+			c.ensureChannel(name)
+		}
+	}
+}
+
+func handlerNames[T any](added []handler[T]) string {
+	names := make([]string, len(added))
+	for index, registered := range added {
+		names[index] = registered.name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func (c *Conveyer[T]) RegisterDecorator(
+	decoratorFunction DecoratorFunc[T],
 	input string,
 	output string,
+	options ...HandlerOption,
 ) {
-	inputChannel := c.getChannel(input)
-	outputChannel := c.getChannel(output)
-
-	c.handlers = append(c.handlers, func(ctx context.Context) error {
-		return decoratorFunction(ctx, inputChannel, outputChannel)
-	})
+	_ = c.AddDecorator(decoratorFunction, input, output, options...)
 }
 
-func (c *Conveyer) RegisterMultiplexer(
-	multiplexerFunction func(context.Context, []chan string, chan string) error,
+func (c *Conveyer[T]) RegisterMultiplexer(
+	multiplexerFunction MultiplexerFunc[T],
 	inputs []string,
 	output string,
+	options ...HandlerOption,
 ) {
-	inputChannels := make([]chan string, len(inputs))
-	for index, name := range inputs {
-		inputChannels[index] = c.getChannel(name)
-	}
+	_ = c.AddMultiplexer(multiplexerFunction, inputs, output, options...)
+}
 
-	outputChannel := c.getChannel(output)
+func (c *Conveyer[T]) RegisterSeparator(
+	separatorFunction SeparatorFunc[T],
+	input string,
+	outputs []string,
+	options ...HandlerOption,
+) {
+	_ = c.AddSeparator(separatorFunction, input, outputs, options...)
+}
 
-	c.handlers = append(c.handlers, func(ctx context.Context) error {
-		return multiplexerFunction(ctx, inputChannels, outputChannel)
-	})
+func (c *Conveyer[T]) AddDecorator(
+	decoratorFunction DecoratorFunc[T],
+	input string,
+	output string,
+	options ...HandlerOption,
+) error {
+	return c.register(KindDecorator, []string{input}, []string{output}, options,
+		func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+			return decoratorFunction(ctx, inputs[0], outputs[0])
+		})
 }
 
-func (c *Conveyer) RegisterSeparator(
-	separatorFunction func(context.Context, chan string, []chan string) error,
+func (c *Conveyer[T]) AddMultiplexer(
+	multiplexerFunction MultiplexerFunc[T],
+	inputs []string,
+	output string,
+	options ...HandlerOption,
+) error {
+	return c.register(KindMultiplexer, inputs, []string{output}, options,
+		func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+			return multiplexerFunction(ctx, inputs, outputs[0])
+		})
+}
+
+func (c *Conveyer[T]) AddSeparator(
+	separatorFunction SeparatorFunc[T],
 	input string,
 	outputs []string,
-) {
-	inputChannel := c.getChannel(input)
-	outputChannels := make([]chan string, len(outputs))
+	options ...HandlerOption,
+) error {
+	return c.register(KindSeparator, []string{input}, outputs, options,
+		func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+			return separatorFunction(ctx, inputs[0], outputs)
+		})
+}
 
-	for index, name := range outputs {
-		outputChannels[index] = c.getChannel(name)
-	}
+func (c *Conveyer[T]) DeclareInputs(names ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	c.handlers = append(c.handlers, func(ctx context.Context) error {
-		return separatorFunction(ctx, inputChannel, outputChannels)
-	})
+	c.declaredInputs = append(c.declaredInputs, names...)
 }
 
-func (c *Conveyer) Run(ctx context.Context) error {
+func (c *Conveyer[T]) DeclareOutputs(names ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.declaredOutputs = append(c.declaredOutputs, names...)
+}
+
+func (c *Conveyer[T]) Run(ctx context.Context) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("validating conveyer: %w", err)
+	}
+
 	c.mutex.Lock()
 
 	if c.isRunning {
@@ -104,58 +378,249 @@ func (c *Conveyer) Run(ctx context.Context) error {
 		return errAlreadyRunning
 	}
 
+	if c.isShutdown {
+		c.mutex.Unlock()
+
+		return ErrShutdown
+	}
+
+	if err := c.restoreCheckpoint(); err != nil {
+		c.mutex.Unlock()
+
+		return fmt.Errorf("restoring checkpoint: %w", err)
+	}
+
 	c.isRunning = true
 	ctx, cancel := context.WithCancel(ctx)
 	c.cancelFunc = cancel
-	c.mutex.Unlock()
-
+	c.isolated = nil
+	c.lost = make(map[string][]T)
+	c.abort = make(chan struct{})
+	done := make(chan struct{})
+	c.done = done
 	errorGroup, ctxWithCancel := errgroup.WithContext(ctx)
+	c.group, c.groupCtx = errorGroup, ctxWithCancel
+	c.startRouters(ctxWithCancel)
 
-	for _, handler := range c.handlers {
-		handlerCopy := handler
+	if len(c.durable) > 0 {
+		c.sending.Add(1)
 
 		errorGroup.Go(func() error {
-			return handlerCopy(ctxWithCancel)
+			defer c.sending.Done()
+
+			return c.replayDurable(ctxWithCancel)
 		})
 	}
 
+	c.mutex.Unlock()
+
+	c.emit(newEvent(EventRunStarted, "", "", 0, nil))
+
+	c.mutex.Lock()
+	for _, registered := range c.handlers {
+		c.startHandler(registered)
+	}
+	c.mutex.Unlock()
+
+	defer close(done)
+
+	stopCheckpoints := make(chan struct{})
+	checkpointsDone := make(chan struct{})
+
+	go func() {
+		defer close(checkpointsDone)
+
+		c.runCheckpoints(stopCheckpoints)
+	}()
+
 	err := errorGroup.Wait()
 
+	close(stopCheckpoints)
+	<-checkpointsDone
+
 	c.mutex.Lock()
 	c.isRunning = false
 	c.cancelFunc = nil
+	c.group, c.groupCtx = nil, nil
+	isolated := c.isolated
 	c.mutex.Unlock()
 
-	return err //nolint:wrapcheck
+	cancel()
+	c.checkpointOrReport()
+
+	err = errors.Join(append([]error{err}, isolated...)...)
+	c.emit(newEvent(EventRunStopped, "", "", 0, err))
+
+	return err
 }
 
-func (c *Conveyer) Send(input string, data string) error {
+func (c *Conveyer[T]) lookupChannels(names []string) []chan T {
 	c.mutex.RLock()
-	channel, exists := c.channels[input]
-	c.mutex.RUnlock()
+	defer c.mutex.RUnlock()
+
+	channels := make([]chan T, len(names))
+
+	for index, name := range names {
+		channels[index] = c.channels[name]
+	}
+
+	return channels
+}
+
+func (c *Conveyer[T]) specs() []handlerSpec {
+	specs := make([]handlerSpec, len(c.handlers))
+
+	for index, registered := range c.handlers {
+		specs[index] = registered.handlerSpec
+	}
+
+	return specs
+}
 
+func (c *Conveyer[T]) acquireInput(input string) (chan T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.isShutdown {
+		return nil, ErrShutdown
+	}
+
+	channel, exists := c.channels[input]
 	if !exists {
-		return fmt.Errorf("%w", errChanNotFound)
+		return nil, fmt.Errorf("%w", ErrChanNotFound)
 	}
 
-	channel <- data
+	c.sending.Add(1)
 
-	return nil
+	return channel, nil
 }
 
-func (c *Conveyer) Recv(output string) (string, error) {
+func (c *Conveyer[T]) lookupOutput(output string) (chan T, error) {
 	c.mutex.RLock()
-	channel, exists := c.channels[output]
-	c.mutex.RUnlock()
+	defer c.mutex.RUnlock()
 
+	channel, exists := c.channels[output]
 	if !exists {
-		return "", fmt.Errorf("%w", errChanNotFound)
+		return nil, fmt.Errorf("%w", ErrChanNotFound)
 	}
 
-	data, ok := <-channel
+	return channel, nil
+}
+
+func (c *Conveyer[T]) Send(input string, data T) error {
+	return c.SendContext(context.Background(), input, data)
+}
+
+func (c *Conveyer[T]) SendContext(ctx context.Context, input string, data T) error {
+	channel, err := c.acquireInput(input)
+	if err != nil {
+		return err
+	}
+
+	defer c.sending.Done()
+
+	if err := c.persist(input, data, func() error {
+		return c.sendBlocking(ctx, input, channel, data)
+	}); err != nil {
+		return fmt.Errorf("sending to %q: %w", input, err)
+	}
+
+	c.observeChannel(input, channel, true)
+
+	return nil
+}
+
+func (c *Conveyer[T]) TrySend(input string, data T) error {
+	channel, err := c.acquireInput(input)
+	if err != nil {
+		return err
+	}
+
+	defer c.sending.Done()
+
+	if err := c.persist(input, data, func() error {
+		select {
+		case channel <- data:
+			return nil
+		default:
+			return fmt.Errorf("%w", ErrChanFull)
+		}
+	}); err != nil {
+		return err
+	}
+
+	c.observeChannel(input, channel, true)
+
+	return nil
+}
+
+func (c *Conveyer[T]) Recv(output string) (T, error) {
+	return c.RecvContext(context.Background(), output)
+}
+
+func (c *Conveyer[T]) RecvContext(ctx context.Context, output string) (T, error) {
+	if _, isDeadLetter := c.deadLetterChannel(output); isDeadLetter {
+		letter, err := c.RecvDeadLetter(ctx)
+
+		return letter.Data, err
+	}
+
+	channel, err := c.lookupReadable(output)
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	select {
+	case data, ok := <-channel:
+		return c.received(output, channel, data, ok)
+	case <-ctx.Done():
+		var zero T
+
+		return zero, fmt.Errorf("receiving from %q: %w", output, ctx.Err())
+	}
+}
+
+func (c *Conveyer[T]) TryRecv(output string) (T, error) {
+	if deadLetters, isDeadLetter := c.deadLetterChannel(output); isDeadLetter {
+		select {
+		case letter := <-deadLetters:
+			return letter.Data, nil
+		default:
+			var zero T
+
+			return zero, fmt.Errorf("%w", ErrNoDataAvailable)
+		}
+	}
+
+	channel, err := c.lookupReadable(output)
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	select {
+	case data, ok := <-channel:
+		return c.received(output, channel, data, ok)
+	default:
+		var zero T
+
+		return zero, fmt.Errorf("%w", ErrNoDataAvailable)
+	}
+}
+
+func (c *Conveyer[T]) received(output string, channel chan T, data T, ok bool) (T, error) {
 	if !ok {
-		return "undefined", nil
+		var zero T
+
+		return zero, fmt.Errorf("receiving from %q: %w", output, ErrClosed)
 	}
 
+	c.acknowledge(output)
+	c.signalRead(output)
+	c.observeChannel(output, channel, false)
+
 	return data, nil
 }
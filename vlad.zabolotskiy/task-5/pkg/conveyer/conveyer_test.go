@@ -0,0 +1,89 @@
+package conveyer_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID    int
+	Value string
+}
+
+func TestConveyer_StringPipeline(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "hello"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: hello", data)
+
+	require.NoError(t, harness.Stop())
+
+	_, err = pipeline.Recv("out")
+	require.ErrorIs(t, err, conveyer.ErrClosed)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestConveyer_TypedPipeline(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.NewTyped[record](1)
+	pipeline.RegisterDecorator(
+		func(ctx context.Context, input chan record, output chan record) error {
+			defer close(output)
+
+			for {
+				var item record
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case received, ok := <-input:
+					if !ok {
+						return nil
+					}
+
+					item = received
+				}
+
+				item.Value += "!"
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case output <- item:
+				}
+			}
+		},
+		"in",
+		"out",
+	)
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", record{ID: 1, Value: "a"}))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, record{ID: 1, Value: "a!"}, data)
+
+	_, err = pipeline.Recv("missing")
+	require.Error(t, err)
+	require.NoError(t, harness.Stop())
+}
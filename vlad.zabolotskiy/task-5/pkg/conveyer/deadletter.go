@@ -0,0 +1,124 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoDeadLetter       = errors.New("dead letter channel is not configured")
+	ErrDeadLetterFull     = errors.New("dead letter channel is full")
+	ErrDeadLetterShadows  = errors.New("dead letter channel shadows a channel")
+	ErrDeadLetterNotEmpty = errors.New("dead letter channel still holds letters")
+)
+
+type DeadLetter[T any] struct {
+	Data    T
+	Reason  string
+	Handler string
+}
+
+type rejectKey struct{}
+
+type rejectFunc[T any] func(ctx context.Context, data T, reason string) bool
+
+func Reject[T any](ctx context.Context, data T, reason string) bool {
+	reject, ok := ctx.Value(rejectKey{}).(rejectFunc[T])
+	if !ok {
+		return false
+	}
+
+	return reject(ctx, data, reason)
+}
+
+func WithRejecter[T any](
+	ctx context.Context,
+	reject func(ctx context.Context, data T, reason string) bool,
+) context.Context {
+	return context.WithValue(ctx, rejectKey{}, rejectFunc[T](reject))
+}
+
+func (c *Conveyer[T]) SetDeadLetter(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.channels[name]; exists {
+		return fmt.Errorf("%w: %q", ErrDeadLetterShadows, name)
+	}
+
+	if c.deadLetters != nil && c.deadLetterName != name && len(c.deadLetters) > 0 {
+		return fmt.Errorf("replacing %q: %w", c.deadLetterName, ErrDeadLetterNotEmpty)
+	}
+
+	c.deadLetterName = name
+
+	if c.deadLetters == nil {
+		c.deadLetters = make(chan DeadLetter[T], c.size)
+	}
+
+	return nil
+}
+
+func (c *Conveyer[T]) withRejecter(ctx context.Context, handler string) context.Context {
+	c.mutex.RLock()
+	deadLetters := c.deadLetters
+	c.mutex.RUnlock()
+
+	return context.WithValue(ctx, rejectKey{}, rejectFunc[T](func(ctx context.Context, data T, reason string) bool {
+		if deadLetters == nil {
+			c.emit(newEvent(EventMessageDropped, handler, "", 0, fmt.Errorf("%w: %s", ErrNoDeadLetter, reason)))
+
+			return false
+		}
+
+		if ctx.Err() != nil {
+			c.emit(newEvent(EventMessageDropped, handler, "", 0, fmt.Errorf("rejecting %s: %w", reason, ctx.Err())))
+
+			return false
+		}
+
+		select {
+		case deadLetters <- DeadLetter[T]{Data: data, Reason: reason, Handler: handler}:
+		default:
+			c.mutex.Lock()
+			name := c.deadLetterName
+			c.lost[name] = append(c.lost[name], data)
+			c.mutex.Unlock()
+
+			c.emit(newEvent(EventMessageDropped, handler, name, 0, ErrDeadLetterFull))
+		}
+
+		return true
+	}))
+}
+
+func (c *Conveyer[T]) deadLetterChannel(name string) (chan DeadLetter[T], bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.deadLetters == nil || name != c.deadLetterName {
+		return nil, false
+	}
+
+	return c.deadLetters, true
+}
+
+func (c *Conveyer[T]) RecvDeadLetter(ctx context.Context) (DeadLetter[T], error) {
+	c.mutex.RLock()
+	deadLetters := c.deadLetters
+	c.mutex.RUnlock()
+
+	var zero DeadLetter[T]
+
+	if deadLetters == nil {
+		return zero, ErrNoDeadLetter
+	}
+
+	select {
+	case letter := <-deadLetters:
+		return letter, nil
+	case <-ctx.Done():
+		return zero, fmt.Errorf("receiving dead letter: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,124 @@
+package conveyer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_DeadLetter(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(2)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "decorated")
+	pipeline.RegisterMultiplexer(handlers.MultiplexerFunc, []string{"decorated"}, "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "no decorator"))
+	require.NoError(t, pipeline.Send("in", "no multiplexer"))
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	letter, err := pipeline.RecvDeadLetter(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, conveyer.DeadLetter[string]{
+		Data:    "no decorator",
+		Reason:  handlers.ErrCantBeDecorated.Error(),
+		Handler: "decorator[0]",
+	}, letter)
+
+	data, err = pipeline.Recv("dlq")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: no multiplexer", data)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestConveyer_DeadLetterFull(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "no decorator 1"))
+	require.NoError(t, pipeline.Send("in", "no decorator 2"))
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	for event := range events {
+		if event.Kind == conveyer.EventMessageDropped {
+			require.ErrorIs(t, event.Err, conveyer.ErrDeadLetterFull)
+			require.Equal(t, "dlq", event.Channel)
+
+			break
+		}
+	}
+
+	letter, err := pipeline.RecvDeadLetter(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "no decorator 1", letter.Data)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestConveyer_SetDeadLetterErrors(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	require.ErrorIs(t, pipeline.SetDeadLetter("out"), conveyer.ErrDeadLetterShadows)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "out", "dlq")
+	require.ErrorIs(t, pipeline.Validate(), conveyer.ErrDeadLetterShadows)
+}
+
+func TestConveyer_SetDeadLetterKeepsLetters(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(2)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "no decorator"))
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	_, err := pipeline.Recv("out")
+	require.NoError(t, err)
+
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	require.ErrorIs(t, pipeline.SetDeadLetter("rejected"), conveyer.ErrDeadLetterNotEmpty)
+
+	data, err := pipeline.Recv("dlq")
+	require.NoError(t, err)
+	require.Equal(t, "no decorator", data)
+
+	require.NoError(t, pipeline.SetDeadLetter("rejected"))
+
+	require.NoError(t, harness.Stop())
+}
@@ -0,0 +1,91 @@
+// Package conveyer wires handlers together with named channels.
+//
+// Conveyer is generic over the message type. Code written against the former
+// non-generic *Conveyer should use *StringConveyer, an alias for
+// Conveyer[string], which New still returns.
+//
+// Closing rules:
+//
+//   - A channel that no handler writes to is a source. Callers feed it with
+//     Send, and only the conveyer closes it, during Shutdown, once in-flight
+//     sends have finished.
+//   - A channel that a handler writes to belongs to that handler. The handler
+//     closes it when it returns, usually with defer close(output). When a
+//     handler is restarted, the conveyer does not propagate the close. It closes
+//     the channel only after the last attempt. RemoveHandler closes the outputs
+//     of the removed handler that no other handler reads, and a handler
+//     registered later as their writer gets a fresh channel. Outputs that still
+//     have readers stay open, so a handler registered later as their writer
+//     takes over the same channel and the readers keep running.
+//   - A handler never closes its inputs. They are closed upstream, and a closed
+//     input tells the handler to finish and close its outputs.
+//   - Cancelling the context passed to Run stops the handlers. Outputs they
+//     close on the way out are closed as usual, and source channels stay open.
+//     Anything still buffered stays readable.
+//   - When Shutdown runs out of time, handler inputs are closed first, and
+//     whatever the handlers still emit is reported as lost. Handlers that do not
+//     finish within a short grace period are cancelled.
+//
+// Handlers registered while the conveyer runs are validated against the live
+// topology. An invalid addition is not started and is reported with a
+// handler-rejected event. RegisterDecorator, RegisterMultiplexer and
+// RegisterSeparator report it only that way. AddDecorator, AddMultiplexer and
+// AddSeparator also return the error. Mount validates a whole stage at once,
+// together with the ports it declares, and adds either every handler of the
+// stage or none of them. Ports of nested stages that are left unbound are
+// declared as well.
+//
+// Handlers see their outputs through unbuffered proxies, so the length and
+// capacity of those channels say nothing about the channels behind them.
+// OutputRoom reports how many more messages the real output can take, counting
+// one the conveyer is about to accept. Outside a conveyer it reports false.
+//
+// Recv, RecvContext and TryRecv return ErrClosed, which matches io.EOF, once a
+// channel is closed and drained. Stream and StreamContext stop at that point.
+// Any other error, such as ErrChanNotFound or a done context, is yielded once
+// before the stream stops.
+//
+// Hooks registered with OnEvent run on the goroutine that emits the event, so
+// they should return quickly. A hook that panics is recovered and does not stop
+// the conveyer or the hooks after it. The panic is reported to subscribers, but
+// not to hooks, with a hook-panicked event carrying ErrHookPanicked.
+//
+// Events never wait for subscribers. When a subscriber's buffer is full, the
+// event is dropped for that subscriber only, and DroppedEvents reports how many
+// events it has missed so far.
+//
+// Without a dead letter channel, Reject returns false and reports the message
+// with a message-dropped event carrying ErrNoDeadLetter. Reject never blocks on
+// the dead letter channel. When it is full, the letter is recorded as lost and
+// reported with a message-dropped event carrying ErrDeadLetterFull. WithRejecter
+// installs a rejecter for another message type, so a wrapper can translate what
+// its inner handler rejects, for example back into the envelope the message
+// arrived in.
+//
+// Parallel with Ordered runs the decorator once per message, so a decorator may
+// emit any number of messages for each input, including none, and they still
+// come out in input order. State kept inside the decorator does not carry over
+// between messages in this mode.
+//
+// A durable channel appends each message to its log before the message enters
+// the channel and acknowledges it once a handler has taken it. Delivery is
+// therefore at-most-once after that hand-off: a message the handler was holding
+// when the process stopped is not replayed. A failed acknowledgement is
+// reported with an ack-failed event, and the message may be delivered again on
+// the next Run.
+//
+// Handler state is checkpointed periodically and once more when Run returns. A
+// failed checkpoint is reported with a checkpoint-failed event and does not
+// change the result of Run. Outside a conveyer, StateFrom returns a fresh State
+// that nothing reads back, so values stored there are discarded.
+//
+// Call sends a message tagged by the configured Correlator and waits for the
+// reply with the same ID. The first Call on an output reserves it for replies,
+// and from then on Recv, TryRecv and Stream on that output return
+// ErrReservedForCalls. Replies nobody waits for are rejected like handler data,
+// so they end up on the dead letter channel when one is set. Calls waiting on a
+// closed output return ErrClosed, and calls still waiting when Run returns get
+// ErrNotRunning. Replies are routed only while Run is active; a Call made
+// before Run waits for it to start. The reservation outlives Run until
+// ReleaseOutput drops it, which fails with ErrCallsPending while calls wait.
+package conveyer
@@ -0,0 +1,233 @@
+package conveyer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+type DurableLog interface {
+	Append(payload []byte) (uint64, error)
+	Ack(offset uint64) error
+	Replay(visit func(offset uint64, payload []byte) error) error
+}
+
+type Codec[T any] interface {
+	Encode(data T) ([]byte, error)
+	Decode(payload []byte) (T, error)
+}
+
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(data T) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encoding message: %w", err)
+	}
+
+	return payload, nil
+}
+
+func (JSONCodec[T]) Decode(payload []byte) (T, error) {
+	var data T
+
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return data, fmt.Errorf("decoding message: %w", err)
+	}
+
+	return data, nil
+}
+
+type durableEntry struct {
+	offset    uint64
+	discarded bool
+}
+
+type durableChannel[T any] struct {
+	log      DurableLog
+	codec    Codec[T]
+	writing  sync.Mutex
+	mutex    sync.Mutex
+	queue    []durableEntry
+	replayed bool
+}
+
+func (c *Conveyer[T]) SetDurable(name string, durableLog DurableLog, codec Codec[T]) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.ensureChannel(name)
+
+	c.durable[name] = &durableChannel[T]{
+		log:      durableLog,
+		codec:    codec,
+		writing:  sync.Mutex{},
+		mutex:    sync.Mutex{},
+		queue:    nil,
+		replayed: false,
+	}
+}
+
+func (c *Conveyer[T]) durableChannel(name string) *durableChannel[T] {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.durable[name]
+}
+
+func (c *Conveyer[T]) persist(name string, data T, send func() error) error {
+	durable := c.durableChannel(name)
+	if durable == nil {
+		return send()
+	}
+
+	payload, err := durable.codec.Encode(data)
+	if err != nil {
+		return fmt.Errorf("persisting to %q: %w", name, err)
+	}
+
+	durable.writing.Lock()
+	defer durable.writing.Unlock()
+
+	offset, err := durable.log.Append(payload)
+	if err != nil {
+		return fmt.Errorf("persisting to %q: %w", name, err)
+	}
+
+	sendErr, discardErr := durable.push(offset, send)
+	if discardErr != nil {
+		c.emit(newEvent(EventAckFailed, "", name, 0, fmt.Errorf("discarding offset %d: %w", offset, discardErr)))
+	}
+
+	return sendErr
+}
+
+func (c *Conveyer[T]) acknowledge(name string) {
+	durable := c.durableChannel(name)
+	if durable == nil {
+		return
+	}
+
+	if err := durable.settle(true); err != nil {
+		c.emit(newEvent(EventAckFailed, "", name, 0, fmt.Errorf("acknowledging: %w", err)))
+	}
+}
+
+func (c *Conveyer[T]) replayDurable(ctx context.Context) error {
+	c.mutex.RLock()
+	durables := make(map[string]*durableChannel[T], len(c.durable))
+
+	for name, durable := range c.durable {
+		durables[name] = durable
+	}
+	c.mutex.RUnlock()
+
+	for name, durable := range durables {
+		channel := c.getChannel(name)
+
+		if err := durable.replay(func(data T) error {
+			return c.sendBlocking(ctx, name, channel, data)
+		}); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("replaying %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *durableChannel[T]) push(offset uint64, send func() error) (error, error) {
+	d.mutex.Lock()
+	d.queue = append(d.queue, durableEntry{offset: offset, discarded: false})
+	d.mutex.Unlock()
+
+	if err := send(); err != nil {
+		d.mutex.Lock()
+		d.queue[len(d.queue)-1].discarded = true
+		settleErr := d.settleLocked(false)
+		d.mutex.Unlock()
+
+		return err, settleErr
+	}
+
+	return nil, nil
+}
+
+func (d *durableChannel[T]) replay(send func(data T) error) error {
+	d.writing.Lock()
+	defer d.writing.Unlock()
+
+	if d.replayed {
+		return nil
+	}
+
+	err := d.log.Replay(func(offset uint64, payload []byte) error {
+		d.mutex.Lock()
+		queued := len(d.queue) > 0 && d.queue[len(d.queue)-1].offset >= offset
+		d.mutex.Unlock()
+
+		if queued {
+			return nil
+		}
+
+		data, err := d.codec.Decode(payload)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		d.mutex.Lock()
+		d.queue = append(d.queue, durableEntry{offset: offset, discarded: false})
+		d.mutex.Unlock()
+
+		if err := send(data); err != nil {
+			d.mutex.Lock()
+			d.queue = d.queue[:len(d.queue)-1]
+			d.mutex.Unlock()
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	d.replayed = true
+
+	return nil
+}
+
+func (d *durableChannel[T]) settle(consumed bool) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.settleLocked(consumed)
+}
+
+func (d *durableChannel[T]) settleLocked(consumed bool) error {
+	var (
+		acked    uint64
+		advanced bool
+	)
+
+	if consumed && len(d.queue) > 0 {
+		acked, advanced = d.queue[0].offset, true
+		d.queue = d.queue[1:]
+	}
+
+	for len(d.queue) > 0 && d.queue[0].discarded {
+		acked, advanced = d.queue[0].offset, true
+		d.queue = d.queue[1:]
+	}
+
+	if !advanced {
+		return nil
+	}
+
+	return d.log.Ack(acked) //nolint:wrapcheck
+}
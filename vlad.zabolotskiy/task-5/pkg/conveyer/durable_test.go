@@ -0,0 +1,169 @@
+package conveyer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/durable"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_DurableReplay(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	log, err := durable.Open(dir)
+	require.NoError(t, err)
+
+	first := conveyer.New(4)
+	first.SetDurable("in", log, conveyer.JSONCodec[string]{})
+	first.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	firstHarness := conveyertest.New(t, first)
+	firstHarness.Start()
+
+	require.NoError(t, first.Send("in", "a"))
+	require.NoError(t, first.Send("in", "b"))
+
+	for _, expected := range []string{"decorated: a", "decorated: b"} {
+		data, err := first.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	}
+
+	require.NoError(t, firstHarness.Stop())
+
+	require.NoError(t, first.Send("in", "c"))
+	require.NoError(t, first.Send("in", "d"))
+	require.NoError(t, log.Close())
+
+	reopened, err := durable.Open(dir)
+	require.NoError(t, err)
+
+	defer reopened.Close()
+
+	second := conveyer.New(4)
+	second.SetDurable("in", reopened, conveyer.JSONCodec[string]{})
+	second.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	secondHarness := conveyertest.New(t, second)
+	secondHarness.Start()
+
+	for _, expected := range []string{"decorated: c", "decorated: d"} {
+		data, err := second.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	}
+
+	require.NoError(t, secondHarness.Stop())
+	require.Equal(t, uint64(0), reopened.Unacked())
+}
+
+func TestConveyer_DurableDiscardsFailedSend(t *testing.T) {
+	t.Parallel()
+
+	log, err := durable.Open(t.TempDir())
+	require.NoError(t, err)
+
+	defer log.Close()
+
+	pipeline := conveyer.New(1)
+	pipeline.SetDurable("in", log, conveyer.JSONCodec[string]{})
+
+	require.NoError(t, pipeline.TrySend("in", "a"))
+	require.ErrorIs(t, pipeline.TrySend("in", "b"), conveyer.ErrChanFull)
+	require.Equal(t, uint64(2), log.Unacked())
+
+	data, err := pipeline.Recv("in")
+	require.NoError(t, err)
+	require.Equal(t, "a", data)
+	require.Equal(t, uint64(0), log.Unacked())
+}
+
+func TestConveyer_ShutdownDuringReplay(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	log, err := durable.Open(dir)
+	require.NoError(t, err)
+
+	for _, data := range []string{"a", "b", "c", "d", "e", "f"} {
+		_, err := log.Append([]byte(`"` + data + `"`))
+		require.NoError(t, err)
+	}
+
+	defer log.Close()
+
+	pipeline := conveyer.New(1)
+	pipeline.SetDurable("in", log, conveyer.JSONCodec[string]{})
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = pipeline.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, harness.Wait())
+	require.NotZero(t, log.Unacked())
+}
+
+var errAckRefused = errors.New("ack refused")
+
+type refusingLog struct {
+	*durable.Log
+}
+
+func (refusingLog) Ack(uint64) error {
+	return errAckRefused
+}
+
+func TestConveyer_DurableReportsAckFailure(t *testing.T) {
+	t.Parallel()
+
+	log, err := durable.Open(t.TempDir())
+	require.NoError(t, err)
+
+	defer log.Close()
+
+	pipeline := conveyer.New(4)
+	pipeline.SetDurable("in", refusingLog{Log: log}, conveyer.JSONCodec[string]{})
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	for event := range events {
+		if event.Kind == conveyer.EventAckFailed {
+			require.Equal(t, "in", event.Channel)
+			require.ErrorIs(t, event.Err, errAckRefused)
+
+			break
+		}
+	}
+
+	require.NoError(t, harness.Stop())
+}
@@ -0,0 +1,141 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+var (
+	ErrHandlerNotFound = errors.New("handler not found")
+	ErrChannelInUse    = errors.New("channel is used by a handler")
+)
+
+type runningHandler struct {
+	runCtx     context.Context //nolint:containedctx
+	cancel     context.CancelFunc
+	done       chan struct{}
+	stopInputs chan struct{}
+	keepOpen   []bool
+	removing   atomic.Bool
+}
+
+func (c *Conveyer[T]) startHandler(registered handler[T]) {
+	handlerCtx, cancel := context.WithCancel(c.groupCtx)
+	state := &runningHandler{
+		runCtx:     c.groupCtx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		stopInputs: make(chan struct{}),
+		keepOpen:   nil,
+		removing:   atomic.Bool{},
+	}
+
+	c.running[registered.name] = state
+	c.liveHandlers++
+
+	c.group.Go(func() error {
+		defer func() {
+			c.mutex.Lock()
+			c.liveHandlers--
+			delete(c.running, registered.name)
+			c.mutex.Unlock()
+
+			cancel()
+			close(state.done)
+		}()
+
+		return c.runHandler(handlerCtx, registered, state)
+	})
+}
+
+func (c *Conveyer[T]) RemoveHandler(ctx context.Context, name string) error {
+	c.mutex.Lock()
+
+	index := slices.IndexFunc(c.handlers, func(registered handler[T]) bool {
+		return registered.name == name
+	})
+	if index < 0 {
+		c.mutex.Unlock()
+
+		return fmt.Errorf("%w: %s", ErrHandlerNotFound, name)
+	}
+
+	registered := c.handlers[index]
+	c.handlers = slices.Delete(c.handlers, index, index+1)
+	delete(c.states, name)
+	state := c.running[name]
+
+	if state != nil {
+		state.keepOpen = c.readOutputs(registered.outputs)
+	}
+
+	c.mutex.Unlock()
+
+	if state == nil {
+		return nil
+	}
+
+	state.removing.Store(true)
+
+	err := c.waitDrained(ctx, registered.inputs, c.lookupChannels(registered.inputs))
+	if err == nil {
+		close(state.stopInputs)
+
+		select {
+		case <-state.done:
+			return nil
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	state.cancel()
+	<-state.done
+
+	return fmt.Errorf("removing %s: %w", name, err)
+}
+
+func (c *Conveyer[T]) readOutputs(outputs []string) []bool {
+	read := make([]bool, len(outputs))
+
+	for index, output := range outputs {
+		read[index] = slices.ContainsFunc(c.handlers, func(registered handler[T]) bool {
+			return slices.Contains(registered.inputs, output)
+		})
+	}
+
+	return read
+}
+
+func (c *Conveyer[T]) rejectPending(ctx context.Context, name string, pending []pendingSlot[T]) {
+	ctx = c.withRejecter(ctx, name)
+
+	for _, slot := range pending {
+		if slot.ok && !Reject(ctx, slot.data, "handler removed") {
+			c.emit(newEvent(EventMessageDropped, name, "", 0, nil))
+		}
+	}
+}
+
+func (c *Conveyer[T]) RemoveChannel(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.channels[name]; !exists {
+		return fmt.Errorf("%w", ErrChanNotFound)
+	}
+
+	for _, registered := range c.handlers {
+		if slices.Contains(registered.inputs, name) || slices.Contains(registered.outputs, name) {
+			return fmt.Errorf("%w: %s used by %s", ErrChannelInUse, name, registered.name)
+		}
+	}
+
+	delete(c.channels, name)
+	delete(c.closedChannels, name)
+
+	return nil
+}
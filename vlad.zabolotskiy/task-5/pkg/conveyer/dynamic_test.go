@@ -0,0 +1,195 @@
+package conveyer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Dynamic(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "middle")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	pipeline.RegisterSeparator(handlers.BroadcastSeparator[string](), "middle", []string{"out"})
+
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	require.NoError(t, pipeline.Send("middle", "b"))
+	require.NoError(t, pipeline.RemoveHandler(context.Background(), "separator[1]"))
+	require.ErrorIs(t, pipeline.RemoveHandler(context.Background(), "separator[1]"), conveyer.ErrHandlerNotFound)
+
+	data, err = pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "b", data)
+
+	require.ErrorIs(t, pipeline.RemoveChannel("middle"), conveyer.ErrChannelInUse)
+	require.NoError(t, pipeline.RemoveChannel("out"))
+
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "middle", "replacement")
+	require.NoError(t, pipeline.Send("in", "c"))
+
+	data, err = pipeline.Recv("replacement")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: c", data)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestConveyer_RemoveHandlerClosesOutputs(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+
+	require.NoError(t, pipeline.Send("in", "a"))
+	require.NoError(t, pipeline.RemoveHandler(context.Background(), "decorator[0]"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	_, err = pipeline.Recv("out")
+	require.ErrorIs(t, err, conveyer.ErrClosed)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestConveyer_RejectsInvalidHotAddition(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+
+	err := pipeline.AddDecorator(handlers.PrefixDecoratorFunc, "other", "out")
+	require.ErrorIs(t, err, conveyer.ErrMultipleWriters)
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerRejected {
+			require.ErrorIs(t, event.Err, conveyer.ErrMultipleWriters)
+
+			break
+		}
+	}
+
+	require.Len(t, pipeline.Status().Handlers, 1)
+
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	data, err := pipeline.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestConveyer_SwapHandler(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "middle")
+	pipeline.RegisterDecorator(handlers.Map(strings.ToUpper), "middle", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for started := 0; started < 2; {
+		if event := <-events; event.Kind == conveyer.EventHandlerStarted {
+			started++
+		}
+	}
+
+	require.NoError(t, pipeline.Send("in", "a"))
+	require.NoError(t, pipeline.RemoveHandler(context.Background(), "decorator[0]"))
+	require.NoError(t, pipeline.AddDecorator(handlers.Map(func(data string) string {
+		return data + "!"
+	}), "in", "middle"))
+	require.NoError(t, pipeline.Send("in", "b"))
+
+	recvCtx, recvCancel := context.WithTimeout(context.Background(), time.Second)
+	defer recvCancel()
+
+	for _, expected := range []string{"DECORATED: A", "B!"} {
+		data, err := pipeline.RecvContext(recvCtx, "out")
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	}
+
+	require.Len(t, pipeline.Status().Handlers, 2)
+
+	report, err := pipeline.Shutdown(recvCtx)
+	require.NoError(t, err)
+	require.Empty(t, report.Lost)
+	require.NoError(t, harness.Wait())
+}
+
+func TestConveyer_ShutdownAfterRemoveHandler(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "other", "sink")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for started := 0; started < 2; {
+		if event := <-events; event.Kind == conveyer.EventHandlerStarted {
+			started++
+		}
+	}
+
+	require.NoError(t, pipeline.RemoveHandler(context.Background(), "decorator[0]"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := pipeline.Shutdown(ctx)
+	require.NoError(t, err)
+	require.NoError(t, harness.Wait())
+}
@@ -0,0 +1,213 @@
+package conveyer
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var ErrHookPanicked = errors.New("event hook panicked")
+
+type EventKind string
+
+const (
+	EventRunStarted        EventKind = "run-started"
+	EventRunStopped        EventKind = "run-stopped"
+	EventHandlerStarted    EventKind = "handler-started"
+	EventHandlerExited     EventKind = "handler-exited"
+	EventHandlerRestarting EventKind = "handler-restarting"
+	EventHandlerIsolated   EventKind = "handler-isolated"
+	EventHandlerRejected   EventKind = "handler-rejected"
+	EventChannelClosed     EventKind = "channel-closed"
+	EventMessageDropped    EventKind = "message-dropped"
+	EventShutdownBegun     EventKind = "shutdown-begun"
+	EventShutdownCompleted EventKind = "shutdown-completed"
+	EventCheckpointFailed  EventKind = "checkpoint-failed"
+	EventHookPanicked      EventKind = "hook-panicked"
+	EventAckFailed         EventKind = "ack-failed"
+)
+
+type Event struct {
+	Kind    EventKind
+	Time    time.Time
+	Handler string
+	Channel string
+	Attempt int
+	Err     error
+}
+
+type Hook func(event Event)
+
+type subscription struct {
+	events  chan Event
+	dropped atomic.Uint64
+}
+
+func newEvent(kind EventKind, handler string, channel string, attempt int, err error) Event {
+	return Event{Kind: kind, Time: time.Now(), Handler: handler, Channel: channel, Attempt: attempt, Err: err}
+}
+
+type HandlerState string
+
+const (
+	HandlerPending    HandlerState = "pending"
+	HandlerRunning    HandlerState = "running"
+	HandlerRestarting HandlerState = "restarting"
+	HandlerStopped    HandlerState = "stopped"
+	HandlerFailed     HandlerState = "failed"
+)
+
+type HandlerStatus struct {
+	Name      string
+	Kind      HandlerKind
+	Inputs    []string
+	Outputs   []string
+	State     HandlerState
+	Restarts  int
+	LastError error
+	Since     time.Time
+}
+
+type Status struct {
+	Running  bool
+	Shutdown bool
+	Handlers []HandlerStatus
+}
+
+func (s Status) Healthy() bool {
+	for _, handler := range s.Handlers {
+		if handler.State == HandlerFailed || handler.State == HandlerRestarting {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Conveyer[T]) OnEvent(hook Hook) {
+	c.eventMutex.Lock()
+	defer c.eventMutex.Unlock()
+
+	c.hooks = append(c.hooks, hook)
+}
+
+func (c *Conveyer[T]) Subscribe(buffer int) (<-chan Event, func()) {
+	c.eventMutex.Lock()
+	defer c.eventMutex.Unlock()
+
+	id := c.nextSubscriber
+	c.nextSubscriber++
+	events := make(chan Event, buffer)
+	c.subscribers[id] = &subscription{events: events, dropped: atomic.Uint64{}}
+
+	return events, func() {
+		c.eventMutex.Lock()
+		defer c.eventMutex.Unlock()
+
+		if _, exists := c.subscribers[id]; exists {
+			delete(c.subscribers, id)
+			close(events)
+		}
+	}
+}
+
+func (c *Conveyer[T]) DroppedEvents(events <-chan Event) uint64 {
+	c.eventMutex.RLock()
+	defer c.eventMutex.RUnlock()
+
+	for _, subscriber := range c.subscribers {
+		if (<-chan Event)(subscriber.events) == events {
+			return subscriber.dropped.Load()
+		}
+	}
+
+	return 0
+}
+
+func (c *Conveyer[T]) emit(event Event) {
+	hooks := c.publish(event)
+
+	for _, hook := range hooks {
+		if recovered := runHook(hook, event); recovered != nil {
+			c.publish(newEvent(EventHookPanicked, event.Handler, event.Channel, event.Attempt,
+				fmt.Errorf("%w on %s: %v", ErrHookPanicked, event.Kind, recovered)))
+		}
+	}
+}
+
+func (c *Conveyer[T]) publish(event Event) []Hook {
+	c.eventMutex.RLock()
+	defer c.eventMutex.RUnlock()
+
+	for _, subscriber := range c.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			subscriber.dropped.Add(1)
+		}
+	}
+
+	return c.hooks
+}
+
+func runHook(hook Hook, event Event) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+
+	hook(event)
+
+	return nil
+}
+
+func (c *Conveyer[T]) setHandlerState(name string, state HandlerState, restarts int, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.states[name] = &HandlerStatus{
+		Name:      name,
+		Kind:      "",
+		Inputs:    nil,
+		Outputs:   nil,
+		State:     state,
+		Restarts:  restarts,
+		LastError: err,
+		Since:     time.Now(),
+	}
+}
+
+func (c *Conveyer[T]) Status() Status {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	status := Status{
+		Running:  c.isRunning,
+		Shutdown: c.isShutdown,
+		Handlers: make([]HandlerStatus, 0, len(c.handlers)),
+	}
+
+	for _, registered := range c.handlers {
+		current := HandlerStatus{
+			Name:      registered.name,
+			Kind:      registered.kind,
+			Inputs:    append([]string(nil), registered.inputs...),
+			Outputs:   append([]string(nil), registered.outputs...),
+			State:     HandlerPending,
+			Restarts:  0,
+			LastError: nil,
+			Since:     time.Time{},
+		}
+
+		if state, exists := c.states[registered.name]; exists {
+			current.State = state.State
+			current.Restarts = state.Restarts
+			current.LastError = state.LastError
+			current.Since = state.Since
+		}
+
+		status.Handlers = append(status.Handlers, current)
+	}
+
+	return status
+}
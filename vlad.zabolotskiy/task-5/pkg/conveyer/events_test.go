@@ -0,0 +1,206 @@
+package conveyer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Events(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	var (
+		mutex  sync.Mutex
+		hooked []conveyer.EventKind
+	)
+
+	pipeline.OnEvent(func(event conveyer.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		hooked = append(hooked, event.Kind)
+	})
+
+	events, unsubscribe := pipeline.Subscribe(16)
+
+	status := pipeline.Status()
+	require.False(t, status.Running)
+	require.Equal(t, conveyer.HandlerPending, status.Handlers[0].State)
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.Equal(t, conveyer.EventRunStarted, (<-events).Kind)
+	require.Equal(t, conveyer.EventHandlerStarted, (<-events).Kind)
+
+	status = pipeline.Status()
+	require.True(t, status.Running)
+	require.True(t, status.Healthy())
+	require.Equal(t, conveyer.HandlerRunning, status.Handlers[0].State)
+	require.Equal(t, "decorator[0]", status.Handlers[0].Name)
+
+	_, err := pipeline.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, harness.Wait())
+
+	expected := []conveyer.Event{
+		{Kind: conveyer.EventShutdownBegun, Time: time.Time{}, Handler: "", Channel: "", Attempt: 0, Err: nil},
+		{Kind: conveyer.EventChannelClosed, Time: time.Time{}, Handler: "", Channel: "in", Attempt: 0, Err: nil},
+		{Kind: conveyer.EventHandlerExited, Time: time.Time{}, Handler: "decorator[0]", Channel: "", Attempt: 0, Err: nil},
+		{
+			Kind: conveyer.EventChannelClosed, Time: time.Time{}, Handler: "decorator[0]", Channel: "out",
+			Attempt: 0, Err: nil,
+		},
+		{Kind: conveyer.EventRunStopped, Time: time.Time{}, Handler: "", Channel: "", Attempt: 0, Err: nil},
+		{Kind: conveyer.EventShutdownCompleted, Time: time.Time{}, Handler: "", Channel: "", Attempt: 0, Err: nil},
+	}
+
+	for _, want := range expected {
+		event := <-events
+		require.False(t, event.Time.IsZero())
+
+		event.Time = time.Time{}
+		require.Equal(t, want, event)
+	}
+
+	unsubscribe()
+
+	_, open := <-events
+	require.False(t, open)
+
+	require.Equal(t, conveyer.HandlerStopped, pipeline.Status().Handlers[0].State)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	require.Len(t, hooked, 2+len(expected))
+}
+
+func TestConveyer_StatusReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed")
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(func(context.Context, chan string, chan string) error {
+		return errFailed
+	}, "in", "out", conveyer.WithIsolation(), conveyer.WithMaxRestarts(1),
+		conveyer.WithRestart(time.Millisecond, time.Millisecond))
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	err := pipeline.Run(context.Background())
+	require.ErrorIs(t, err, errFailed)
+
+	status := pipeline.Status()
+	require.False(t, status.Healthy())
+	require.Equal(t, conveyer.HandlerFailed, status.Handlers[0].State)
+	require.Equal(t, 1, status.Handlers[0].Restarts)
+	require.ErrorIs(t, status.Handlers[0].LastError, errFailed)
+
+	var exits []int
+
+	for len(events) > 0 {
+		if event := <-events; event.Kind == conveyer.EventHandlerExited {
+			require.ErrorIs(t, event.Err, errFailed)
+
+			exits = append(exits, event.Attempt)
+		}
+	}
+
+	require.Equal(t, []int{0, 1}, exits)
+}
+
+func TestConveyer_RejectWithoutDeadLetterEmitsDrop(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	pipeline.OnEvent(func(conveyer.Event) {
+		panic("broken hook")
+	})
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	require.NoError(t, pipeline.Send("in", "no decorator"))
+	require.ErrorIs(t, pipeline.Run(context.Background()), handlers.ErrCantBeDecorated)
+
+	var dropped []conveyer.Event
+
+	for len(events) > 0 {
+		if event := <-events; event.Kind == conveyer.EventMessageDropped {
+			dropped = append(dropped, event)
+		}
+	}
+
+	require.Len(t, dropped, 1)
+	require.Equal(t, "decorator[0]", dropped[0].Handler)
+	require.ErrorIs(t, dropped[0].Err, conveyer.ErrNoDeadLetter)
+	require.False(t, dropped[0].Time.IsZero())
+}
+
+func TestConveyer_ReportsHookPanicsAndDroppedEvents(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	pipeline.OnEvent(func(event conveyer.Event) {
+		if event.Kind == conveyer.EventRunStarted {
+			panic("broken hook")
+		}
+	})
+
+	events, unsubscribe := pipeline.Subscribe(64)
+	defer unsubscribe()
+
+	slow, unsubscribeSlow := pipeline.Subscribe(1)
+	defer unsubscribeSlow()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	var panicked []conveyer.Event
+
+	for event := range events {
+		if event.Kind == conveyer.EventHookPanicked {
+			panicked = append(panicked, event)
+		}
+
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+
+	_, err := pipeline.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, harness.Wait())
+
+	for len(events) > 0 {
+		if event := <-events; event.Kind == conveyer.EventHookPanicked {
+			panicked = append(panicked, event)
+		}
+	}
+
+	require.Len(t, panicked, 1)
+	require.ErrorIs(t, panicked[0].Err, conveyer.ErrHookPanicked)
+	require.ErrorContains(t, panicked[0].Err, "broken hook")
+
+	require.Len(t, slow, 1)
+	require.Positive(t, pipeline.DroppedEvents(slow))
+	require.Zero(t, pipeline.DroppedEvents(events))
+}
@@ -0,0 +1,175 @@
+package conveyer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type GraphChannel struct {
+	Name string
+	Size int
+}
+
+type GraphHandler struct {
+	Name    string
+	Kind    HandlerKind
+	Inputs  []string
+	Outputs []string
+}
+
+type Graph struct {
+	Channels   []GraphChannel
+	Handlers   []GraphHandler
+	DeadLetter *GraphChannel
+}
+
+func (c *Conveyer[T]) Graph() Graph {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	graph := Graph{
+		Channels:   make([]GraphChannel, 0, len(c.channels)),
+		Handlers:   make([]GraphHandler, 0, len(c.handlers)),
+		DeadLetter: nil,
+	}
+
+	if c.deadLetters != nil {
+		graph.DeadLetter = &GraphChannel{Name: c.deadLetterName, Size: cap(c.deadLetters)}
+	}
+
+	for name, channel := range c.channels {
+		graph.Channels = append(graph.Channels, GraphChannel{Name: name, Size: cap(channel)})
+	}
+
+	sort.Slice(graph.Channels, func(i, j int) bool {
+		return graph.Channels[i].Name < graph.Channels[j].Name
+	})
+
+	for _, registered := range c.handlers {
+		graph.Handlers = append(graph.Handlers, GraphHandler{
+			Name:    registered.name,
+			Kind:    registered.kind,
+			Inputs:  append([]string(nil), registered.inputs...),
+			Outputs: append([]string(nil), registered.outputs...),
+		})
+	}
+
+	return graph
+}
+
+func (g Graph) channelSizes() map[string]int {
+	sizes := make(map[string]int, len(g.Channels))
+
+	for _, channel := range g.Channels {
+		sizes[channel.Name] = channel.Size
+	}
+
+	return sizes
+}
+
+func (g Graph) DOT() string {
+	var builder strings.Builder
+
+	sizes := g.channelSizes()
+
+	builder.WriteString("digraph conveyer {\n")
+	builder.WriteString("\trankdir=LR;\n")
+
+	for _, channel := range g.Channels {
+		fmt.Fprintf(&builder, "\t%s [shape=ellipse, label=%s];\n",
+			dotQuote("chan:"+channel.Name), dotQuote(fmt.Sprintf("%s\nsize=%d", channel.Name, channel.Size)))
+	}
+
+	for _, registered := range g.Handlers {
+		fmt.Fprintf(&builder, "\t%s [shape=box, label=%s];\n",
+			dotQuote("handler:"+registered.Name), dotQuote(registered.Name))
+	}
+
+	for _, registered := range g.Handlers {
+		for _, input := range registered.Inputs {
+			fmt.Fprintf(&builder, "\t%s -> %s [label=%s];\n",
+				dotQuote("chan:"+input), dotQuote("handler:"+registered.Name),
+				dotQuote(edgeLabel(registered.Kind, sizes[input])))
+		}
+
+		for _, output := range registered.Outputs {
+			fmt.Fprintf(&builder, "\t%s -> %s [label=%s];\n",
+				dotQuote("handler:"+registered.Name), dotQuote("chan:"+output),
+				dotQuote(edgeLabel(registered.Kind, sizes[output])))
+		}
+	}
+
+	if g.DeadLetter != nil {
+		fmt.Fprintf(&builder, "\t%s [shape=ellipse, style=dashed, label=%s];\n",
+			dotQuote("dlq:"+g.DeadLetter.Name),
+			dotQuote(fmt.Sprintf("%s\ndead letters size=%d", g.DeadLetter.Name, g.DeadLetter.Size)))
+
+		for _, registered := range g.Handlers {
+			fmt.Fprintf(&builder, "\t%s -> %s [style=dashed, label=\"rejected\"];\n",
+				dotQuote("handler:"+registered.Name), dotQuote("dlq:"+g.DeadLetter.Name))
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+func (g Graph) Mermaid() string {
+	var builder strings.Builder
+
+	sizes := g.channelSizes()
+	channelIDs := make(map[string]string, len(g.Channels))
+
+	builder.WriteString("flowchart LR\n")
+
+	for index, channel := range g.Channels {
+		channelIDs[channel.Name] = fmt.Sprintf("c%d", index)
+		fmt.Fprintf(&builder, "    %s([\"%s<br/>size=%d\"])\n",
+			channelIDs[channel.Name], mermaidEscape(channel.Name), channel.Size)
+	}
+
+	for index, registered := range g.Handlers {
+		fmt.Fprintf(&builder, "    h%d[\"%s\"]\n", index, mermaidEscape(registered.Name))
+	}
+
+	for index, registered := range g.Handlers {
+		for _, input := range registered.Inputs {
+			fmt.Fprintf(&builder, "    %s -->|\"%s\"| h%d\n",
+				channelIDs[input], edgeLabel(registered.Kind, sizes[input]), index)
+		}
+
+		for _, output := range registered.Outputs {
+			fmt.Fprintf(&builder, "    h%d -->|\"%s\"| %s\n",
+				index, edgeLabel(registered.Kind, sizes[output]), channelIDs[output])
+		}
+	}
+
+	if g.DeadLetter != nil {
+		fmt.Fprintf(&builder, "    dlq[(\"%s<br/>dead letters size=%d\")]\n",
+			mermaidEscape(g.DeadLetter.Name), g.DeadLetter.Size)
+
+		for index := range g.Handlers {
+			fmt.Fprintf(&builder, "    h%d -.->|\"rejected\"| dlq\n", index)
+		}
+	}
+
+	return builder.String()
+}
+
+func edgeLabel(kind HandlerKind, size int) string {
+	return fmt.Sprintf("%s size=%d", kind, size)
+}
+
+func dotQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func mermaidEscape(value string) string {
+	replacer := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+	return replacer.Replace(value)
+}
@@ -0,0 +1,47 @@
+package conveyer_test
+
+import (
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Graph(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(3)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "decorated")
+	pipeline.RegisterSeparator(handlers.SeparatorFunc, "decorated", []string{"left", "right"})
+
+	graph := pipeline.Graph()
+	require.Len(t, graph.Channels, 4)
+	require.Equal(t, conveyer.GraphChannel{Name: "decorated", Size: 3}, graph.Channels[0])
+	require.Equal(t, "separator[1]", graph.Handlers[1].Name)
+	require.Equal(t, []string{"left", "right"}, graph.Handlers[1].Outputs)
+
+	dot := graph.DOT()
+	require.Contains(t, dot, `"chan:in" -> "handler:decorator[0]" [label="decorator size=3"];`)
+	require.Contains(t, dot, `"handler:separator[1]" -> "chan:right" [label="separator size=3"];`)
+
+	mermaid := graph.Mermaid()
+	require.Contains(t, mermaid, "flowchart LR\n")
+	require.Contains(t, mermaid, `h1 -->|"separator size=3"| c2`)
+}
+
+func TestConveyer_GraphDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(2)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	graph := pipeline.Graph()
+	require.Equal(t, &conveyer.GraphChannel{Name: "dlq", Size: 2}, graph.DeadLetter)
+	require.Len(t, graph.Channels, 2)
+
+	require.Contains(t, graph.DOT(), `"handler:decorator[0]" -> "dlq:dlq" [style=dashed, label="rejected"];`)
+	require.Contains(t, graph.Mermaid(), `h0 -.->|"rejected"| dlq`)
+}
@@ -0,0 +1,40 @@
+package conveyer
+
+import "time"
+
+type MetricsSink interface {
+	ChannelIn(channel string)
+	ChannelOut(channel string)
+	ChannelOccupancy(channel string, length int, size int)
+	HandlerLatency(handler string, latency time.Duration)
+	HandlerError(handler string, err error)
+}
+
+type BlockedSink interface {
+	ChannelBlocked(channel string, blocked time.Duration)
+}
+
+func (c *Conveyer[T]) SetMetrics(sink MetricsSink) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.metrics = sink
+}
+
+func (c *Conveyer[T]) observeChannel(name string, channel chan T, incoming bool) {
+	c.mutex.RLock()
+	sink := c.metrics
+	c.mutex.RUnlock()
+
+	if sink == nil {
+		return
+	}
+
+	if incoming {
+		sink.ChannelIn(name)
+	} else {
+		sink.ChannelOut(name)
+	}
+
+	sink.ChannelOccupancy(name, len(channel), cap(channel))
+}
@@ -0,0 +1,257 @@
+package conveyer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var defaultLatencyBuckets = []float64{ //nolint:gochecknoglobals
+	0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5,
+}
+
+type ChannelMetrics struct {
+	In      uint64
+	Out     uint64
+	Length  int
+	Size    int
+	Blocked time.Duration
+}
+
+type HandlerMetrics struct {
+	Errors       uint64
+	Buckets      []float64
+	BucketCounts []uint64
+	Count        uint64
+	Sum          time.Duration
+}
+
+type MetricsSnapshot struct {
+	Channels map[string]ChannelMetrics
+	Handlers map[string]HandlerMetrics
+}
+
+type InMemoryMetrics struct {
+	mutex    sync.Mutex
+	buckets  []float64
+	channels map[string]*ChannelMetrics
+	handlers map[string]*HandlerMetrics
+}
+
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{
+		mutex:    sync.Mutex{},
+		buckets:  defaultLatencyBuckets,
+		channels: make(map[string]*ChannelMetrics),
+		handlers: make(map[string]*HandlerMetrics),
+	}
+}
+
+func (m *InMemoryMetrics) channel(name string) *ChannelMetrics {
+	metrics, exists := m.channels[name]
+	if !exists {
+		metrics = &ChannelMetrics{In: 0, Out: 0, Length: 0, Size: 0, Blocked: 0}
+		m.channels[name] = metrics
+	}
+
+	return metrics
+}
+
+func (m *InMemoryMetrics) handler(name string) *HandlerMetrics {
+	metrics, exists := m.handlers[name]
+	if !exists {
+		metrics = &HandlerMetrics{
+			Errors:       0,
+			Buckets:      m.buckets,
+			BucketCounts: make([]uint64, len(m.buckets)),
+			Count:        0,
+			Sum:          0,
+		}
+		m.handlers[name] = metrics
+	}
+
+	return metrics
+}
+
+func (m *InMemoryMetrics) ChannelIn(channel string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.channel(channel).In++
+}
+
+func (m *InMemoryMetrics) ChannelOut(channel string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.channel(channel).Out++
+}
+
+func (m *InMemoryMetrics) ChannelOccupancy(channel string, length int, size int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	metrics := m.channel(channel)
+	metrics.Length = length
+	metrics.Size = size
+}
+
+func (m *InMemoryMetrics) ChannelBlocked(channel string, blocked time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.channel(channel).Blocked += blocked
+}
+
+func (m *InMemoryMetrics) HandlerLatency(handler string, latency time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	metrics := m.handler(handler)
+	metrics.Count++
+	metrics.Sum += latency
+
+	for index, bound := range metrics.Buckets {
+		if latency.Seconds() <= bound {
+			metrics.BucketCounts[index]++
+		}
+	}
+}
+
+func (m *InMemoryMetrics) HandlerError(handler string, _ error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.handler(handler).Errors++
+}
+
+func (m *InMemoryMetrics) Snapshot() MetricsSnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshot := MetricsSnapshot{
+		Channels: make(map[string]ChannelMetrics, len(m.channels)),
+		Handlers: make(map[string]HandlerMetrics, len(m.handlers)),
+	}
+
+	for name, metrics := range m.channels {
+		snapshot.Channels[name] = *metrics
+	}
+
+	for name, metrics := range m.handlers {
+		copied := *metrics
+		copied.BucketCounts = append([]uint64(nil), metrics.BucketCounts...)
+		snapshot.Handlers[name] = copied
+	}
+
+	return snapshot
+}
+
+func (m *InMemoryMetrics) WritePrometheus(writer io.Writer) error {
+	return m.Snapshot().WritePrometheus(writer)
+}
+
+func (m *InMemoryMetrics) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	if err := m.WritePrometheus(writer); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s MetricsSnapshot) WritePrometheus(writer io.Writer) error {
+	buffered := bufio.NewWriter(writer)
+
+	channels := sortedKeys(s.Channels)
+	handlers := sortedKeys(s.Handlers)
+
+	writeHeader(buffered, "conveyer_channel_messages_in_total", "counter", "Messages written to a channel.")
+
+	for _, name := range channels {
+		fmt.Fprintf(buffered, "conveyer_channel_messages_in_total{channel=%s} %d\n",
+			dotQuote(name), s.Channels[name].In)
+	}
+
+	writeHeader(buffered, "conveyer_channel_messages_out_total", "counter", "Messages read from a channel.")
+
+	for _, name := range channels {
+		fmt.Fprintf(buffered, "conveyer_channel_messages_out_total{channel=%s} %d\n",
+			dotQuote(name), s.Channels[name].Out)
+	}
+
+	writeHeader(buffered, "conveyer_channel_buffer_length", "gauge", "Messages currently buffered in a channel.")
+
+	for _, name := range channels {
+		fmt.Fprintf(buffered, "conveyer_channel_buffer_length{channel=%s} %d\n",
+			dotQuote(name), s.Channels[name].Length)
+	}
+
+	writeHeader(buffered, "conveyer_channel_buffer_size", "gauge", "Buffer capacity of a channel.")
+
+	for _, name := range channels {
+		fmt.Fprintf(buffered, "conveyer_channel_buffer_size{channel=%s} %d\n",
+			dotQuote(name), s.Channels[name].Size)
+	}
+
+	writeHeader(buffered, "conveyer_channel_send_blocked_seconds_total", "counter",
+		"Time producers spent blocked sending to a full channel.")
+
+	for _, name := range channels {
+		fmt.Fprintf(buffered, "conveyer_channel_send_blocked_seconds_total{channel=%s} %s\n",
+			dotQuote(name), strconv.FormatFloat(s.Channels[name].Blocked.Seconds(), 'g', -1, 64))
+	}
+
+	writeHeader(buffered, "conveyer_handler_errors_total", "counter", "Errors returned by a handler.")
+
+	for _, name := range handlers {
+		fmt.Fprintf(buffered, "conveyer_handler_errors_total{handler=%s} %d\n",
+			dotQuote(name), s.Handlers[name].Errors)
+	}
+
+	writeHeader(buffered, "conveyer_handler_latency_seconds", "histogram", "Per-message handler latency.")
+
+	for _, name := range handlers {
+		writeHistogram(buffered, name, s.Handlers[name])
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+
+	return nil
+}
+
+func writeHeader(writer io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeHistogram(writer io.Writer, name string, metrics HandlerMetrics) {
+	label := dotQuote(name)
+
+	for index, bound := range metrics.Buckets {
+		fmt.Fprintf(writer, "conveyer_handler_latency_seconds_bucket{handler=%s,le=\"%s\"} %d\n",
+			label, strconv.FormatFloat(bound, 'g', -1, 64), metrics.BucketCounts[index])
+	}
+
+	fmt.Fprintf(writer, "conveyer_handler_latency_seconds_bucket{handler=%s,le=\"+Inf\"} %d\n", label, metrics.Count)
+	fmt.Fprintf(writer, "conveyer_handler_latency_seconds_sum{handler=%s} %s\n",
+		label, strconv.FormatFloat(metrics.Sum.Seconds(), 'g', -1, 64))
+	fmt.Fprintf(writer, "conveyer_handler_latency_seconds_count{handler=%s} %d\n", label, metrics.Count)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
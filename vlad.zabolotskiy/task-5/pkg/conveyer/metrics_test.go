@@ -0,0 +1,52 @@
+package conveyer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Metrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := conveyer.NewInMemoryMetrics()
+
+	pipeline := conveyer.New(2)
+	pipeline.SetMetrics(metrics)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "a"))
+	require.NoError(t, pipeline.Send("in", "b"))
+
+	for range 2 {
+		_, err := pipeline.Recv("out")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, pipeline.Send("in", "no decorator"))
+	require.ErrorIs(t, harness.Wait(), handlers.ErrCantBeDecorated)
+
+	snapshot := metrics.Snapshot()
+	require.Equal(t, uint64(3), snapshot.Channels["in"].In)
+	require.Equal(t, uint64(3), snapshot.Channels["in"].Out)
+	require.Equal(t, uint64(2), snapshot.Channels["out"].In)
+	require.Equal(t, uint64(2), snapshot.Channels["out"].Out)
+	require.Equal(t, 2, snapshot.Channels["out"].Size)
+	require.Equal(t, uint64(2), snapshot.Handlers["decorator[0]"].Count)
+	require.Equal(t, uint64(1), snapshot.Handlers["decorator[0]"].Errors)
+
+	var exposition bytes.Buffer
+
+	require.NoError(t, metrics.WritePrometheus(&exposition))
+	require.Contains(t, exposition.String(), `conveyer_channel_messages_in_total{channel="in"} 3`)
+	require.Contains(t, exposition.String(), `conveyer_handler_latency_seconds_count{handler="decorator[0]"} 2`)
+	require.Contains(t, exposition.String(), `conveyer_handler_errors_total{handler="decorator[0]"} 1`)
+}
@@ -0,0 +1,316 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var ErrInvalidWorkers = errors.New("workers count must be positive")
+
+type Ordering bool
+
+const (
+	Unordered Ordering = false
+	Ordered   Ordering = true
+)
+
+type parallelWorker[T any] struct {
+	input  chan T
+	output chan T
+	done   chan struct{}
+}
+
+type sequenced[T any] struct {
+	seq  int
+	data T
+	done bool
+}
+
+func Parallel[T any](decorator DecoratorFunc[T], workers int, ordering Ordering) DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if workers <= 0 {
+			return ErrInvalidWorkers
+		}
+
+		group, groupCtx := errgroup.WithContext(ctx)
+
+		if ordering == Ordered {
+			runOrdered(groupCtx, group, decorator, workers, input, output)
+
+			return group.Wait() //nolint:wrapcheck
+		}
+
+		pool := make([]*parallelWorker[T], workers)
+		shared := make(chan T)
+
+		for index := range pool {
+			worker := &parallelWorker[T]{
+				input:  shared,
+				output: make(chan T),
+				done:   make(chan struct{}),
+			}
+			pool[index] = worker
+
+			group.Go(func() error {
+				defer close(worker.done)
+
+				return decorator(groupCtx, worker.input, worker.output)
+			})
+		}
+
+		runUnordered(groupCtx, group, input, shared, output, pool)
+
+		return group.Wait() //nolint:wrapcheck
+	}
+}
+
+func runUnordered[T any](
+	ctx context.Context,
+	group *errgroup.Group,
+	input chan T,
+	shared chan T,
+	output chan T,
+	pool []*parallelWorker[T],
+) {
+	var waitGroup sync.WaitGroup
+
+	group.Go(func() error {
+		defer close(shared)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case data, ok := <-input:
+				if !ok {
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case shared <- data:
+				}
+			}
+		}
+	})
+
+	for _, worker := range pool {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			for {
+				select {
+				case data, ok := <-worker.output:
+					if !ok {
+						return
+					}
+
+					select {
+					case output <- data:
+					case <-ctx.Done():
+						return
+					}
+				case <-worker.done:
+					return
+				}
+			}
+		}()
+	}
+
+	group.Go(func() error {
+		waitGroup.Wait()
+
+		return nil
+	})
+}
+
+func runOrdered[T any](
+	ctx context.Context,
+	group *errgroup.Group,
+	decorator DecoratorFunc[T],
+	workers int,
+	input chan T,
+	output chan T,
+) {
+	tasks := make(chan sequenced[T])
+	events := make(chan sequenced[T])
+	window := make(chan struct{}, 2*workers)
+
+	group.Go(func() error {
+		defer close(tasks)
+
+		for seq := 0; ; seq++ {
+			select {
+			case <-ctx.Done():
+				return nil
+			case window <- struct{}{}:
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case data, ok := <-input:
+				if !ok {
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case tasks <- sequenced[T]{seq: seq, data: data, done: false}:
+				}
+			}
+		}
+	})
+
+	var running sync.WaitGroup
+
+	for range workers {
+		running.Add(1)
+
+		group.Go(func() error {
+			defer running.Done()
+
+			for task := range tasks {
+				if err := runTask(ctx, decorator, task, events); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	go func() {
+		running.Wait()
+		close(events)
+	}()
+
+	group.Go(func() error {
+		collectOrdered(ctx, events, output, window)
+
+		return nil
+	})
+}
+
+func runTask[T any](ctx context.Context, decorator DecoratorFunc[T], task sequenced[T], events chan<- sequenced[T]) error {
+	input := make(chan T, 1)
+	input <- task.data
+	close(input)
+
+	output := make(chan T)
+	result := make(chan error, 1)
+
+	go func() {
+		result <- decorator(ctx, input, output)
+	}()
+
+	publish := func(event sequenced[T]) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case events <- event:
+			return true
+		}
+	}
+
+	for {
+		select {
+		case data, ok := <-output:
+			if !ok {
+				output = nil
+
+				continue
+			}
+
+			if !publish(sequenced[T]{seq: task.seq, data: data, done: false}) {
+				return <-result
+			}
+
+		case err := <-result:
+			if err != nil {
+				return err
+			}
+
+			for _, data := range drained(output) {
+				publish(sequenced[T]{seq: task.seq, data: data, done: false})
+			}
+
+			publish(sequenced[T]{seq: task.seq, data: task.data, done: true})
+
+			return nil
+		}
+	}
+}
+
+func drained[T any](channel chan T) []T {
+	var result []T
+
+	for channel != nil {
+		select {
+		case data, ok := <-channel:
+			if !ok {
+				return result
+			}
+
+			result = append(result, data)
+		default:
+			return result
+		}
+	}
+
+	return result
+}
+
+func collectOrdered[T any](ctx context.Context, events <-chan sequenced[T], output chan T, window <-chan struct{}) {
+	buffered := make(map[int][]T)
+	finished := make(map[int]bool)
+	next := 0
+
+	send := func(data T) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case output <- data:
+			return true
+		}
+	}
+
+	for event := range events {
+		switch {
+		case event.done:
+			finished[event.seq] = true
+		case event.seq == next:
+			if !send(event.data) {
+				return
+			}
+		default:
+			buffered[event.seq] = append(buffered[event.seq], event.data)
+		}
+
+		for finished[next] {
+			delete(finished, next)
+			<-window
+
+			next++
+
+			for _, data := range buffered[next] {
+				if !send(data) {
+					return
+				}
+			}
+
+			delete(buffered, next)
+		}
+	}
+}
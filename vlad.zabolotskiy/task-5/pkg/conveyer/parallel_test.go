@@ -0,0 +1,181 @@
+package conveyer_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+const parallelWorkers = 4
+
+func reversingDecorator(count int) conveyer.DecoratorFunc[string] {
+	finished := make([]chan struct{}, count)
+	for index := range finished {
+		finished[index] = make(chan struct{})
+	}
+
+	return func(ctx context.Context, input chan string, output chan string) error {
+		defer close(output)
+
+		for data := range input {
+			number, err := strconv.Atoi(data)
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			if next := number + 1; next%parallelWorkers != 0 && next < count {
+				select {
+				case <-finished[next]:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+
+			close(finished[number])
+
+			select {
+			case output <- "decorated: " + data:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		return nil
+	}
+}
+
+func runParallel(t *testing.T, ordering conveyer.Ordering, count int) []string {
+	t.Helper()
+
+	pipeline := conveyer.New(count)
+	pipeline.RegisterDecorator(conveyer.Parallel(reversingDecorator(count), parallelWorkers, ordering), "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for index := range count {
+		require.NoError(t, pipeline.Send("in", strconv.Itoa(index)))
+	}
+
+	received := make([]string, 0, count)
+
+	for range count {
+		data, err := pipeline.Recv("out")
+		require.NoError(t, err)
+
+		received = append(received, data)
+	}
+
+	require.NoError(t, harness.Stop())
+
+	return received
+}
+
+func TestParallel_Ordered(t *testing.T) {
+	t.Parallel()
+
+	received := runParallel(t, conveyer.Ordered, 20)
+
+	for index, data := range received {
+		require.Equal(t, "decorated: "+strconv.Itoa(index), data)
+	}
+}
+
+func TestParallel_Unordered(t *testing.T) {
+	t.Parallel()
+
+	received := runParallel(t, conveyer.Unordered, 20)
+	sort.Slice(received, func(i, j int) bool {
+		left, _ := strconv.Atoi(received[i][len("decorated: "):])
+		right, _ := strconv.Atoi(received[j][len("decorated: "):])
+
+		return left < right
+	})
+
+	for index, data := range received {
+		require.Equal(t, "decorated: "+strconv.Itoa(index), data)
+	}
+}
+
+func TestParallel_OrderedSkipsRejected(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	pipeline.RegisterDecorator(
+		conveyer.Parallel(handlers.PrefixDecoratorFunc, 2, conveyer.Ordered), "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for _, data := range []string{"a", "no decorator", "b", "c"} {
+		require.NoError(t, pipeline.Send("in", data))
+	}
+
+	for _, expected := range []string{"decorated: a", "decorated: b", "decorated: c"} {
+		data, err := pipeline.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	}
+
+	data, err := pipeline.Recv("dlq")
+	require.NoError(t, err)
+	require.Equal(t, "no decorator", data)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestParallel_InvalidWorkers(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterDecorator(conveyer.Parallel(handlers.PrefixDecoratorFunc, 0, conveyer.Ordered), "in", "out")
+
+	require.ErrorIs(t, pipeline.Run(context.Background()), conveyer.ErrInvalidWorkers)
+}
+
+func TestParallel_OrderedFilterAndFlatMap(t *testing.T) {
+	t.Parallel()
+
+	even := handlers.Filter(func(data string) bool {
+		number, _ := strconv.Atoi(data)
+
+		return number%2 == 0
+	})
+	twice := handlers.FlatMap(func(data string) []string {
+		return []string{data, data}
+	})
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(conveyer.Parallel(even, 3, conveyer.Ordered), "in", "even")
+	pipeline.RegisterDecorator(conveyer.Parallel(twice, 3, conveyer.Ordered), "even", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	go func() {
+		for index := range 10 {
+			_ = pipeline.Send("in", strconv.Itoa(index))
+		}
+	}()
+
+	var received []string
+
+	for range 10 {
+		data, err := pipeline.Recv("out")
+		require.NoError(t, err)
+
+		received = append(received, data)
+	}
+
+	require.Equal(t, []string{"0", "0", "2", "2", "4", "4", "6", "6", "8", "8"}, received)
+
+	require.NoError(t, harness.Stop())
+}
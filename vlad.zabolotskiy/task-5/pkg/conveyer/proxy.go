@@ -0,0 +1,256 @@
+package conveyer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type pendingSlot[T any] struct {
+	data T
+	ok   bool
+}
+
+func (c *Conveyer[T]) runProxied(
+	ctx context.Context,
+	sink MetricsSink,
+	registered handler[T],
+	inputs []chan T,
+	outputs []chan T,
+	pending []pendingSlot[T],
+	stop <-chan struct{},
+	abort <-chan struct{},
+) ([]bool, error) {
+	inFlight := make([]atomic.Int64, len(outputs))
+
+	handlerCtx, cancel := context.WithCancel(withOutputRoom(ctx, outputs, inFlight))
+	defer cancel()
+
+	outputCtx, cancelOutputs := context.WithCancel(ctx)
+	defer cancelOutputs()
+
+	go func() {
+		select {
+		case <-abort:
+			cancelOutputs()
+		case <-outputCtx.Done():
+		}
+	}()
+
+	var (
+		waitGroup    sync.WaitGroup
+		lastReceived atomic.Int64
+	)
+
+	proxyInputs := make([]chan T, len(inputs))
+
+	for index, input := range inputs {
+		proxyInputs[index] = make(chan T)
+
+		waitGroup.Add(1)
+
+		go func(channelName string, source chan T, proxy chan T, slot *pendingSlot[T]) {
+			defer waitGroup.Done()
+
+			c.forwardInput(handlerCtx, stop, abort, channelName, source, proxy, slot, &lastReceived)
+		}(registered.inputs[index], input, proxyInputs[index], &pending[index])
+	}
+
+	proxyOutputs := make([]chan T, len(outputs))
+	closed := make([]bool, len(outputs))
+	handlerDone := make(chan struct{})
+
+	for index, output := range outputs {
+		proxyOutputs[index] = make(chan T)
+
+		waitGroup.Add(1)
+
+		go func(channelName string, proxy chan T, target chan T, pending *atomic.Int64, isClosed *bool) {
+			defer waitGroup.Done()
+
+			*isClosed = c.forwardOutput(outputCtx, abort, handlerDone, channelName, proxy, target, pending, func() {
+				if sink != nil {
+					sink.HandlerLatency(registered.name, time.Since(time.Unix(0, lastReceived.Load())))
+				}
+			})
+		}(registered.outputs[index], proxyOutputs[index], output, &inFlight[index], &closed[index])
+	}
+
+	err := registered.run(handlerCtx, proxyInputs, proxyOutputs)
+
+	close(handlerDone)
+	cancel()
+	waitGroup.Wait()
+
+	return closed, err
+}
+
+func (c *Conveyer[T]) forwardInput(
+	ctx context.Context,
+	stop <-chan struct{},
+	abort <-chan struct{},
+	name string,
+	source chan T,
+	proxy chan T,
+	slot *pendingSlot[T],
+	lastReceived *atomic.Int64,
+) {
+	for {
+		if slot.ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-abort:
+				close(proxy)
+
+				return
+			case proxy <- slot.data:
+				lastReceived.Store(time.Now().UnixNano())
+				c.acknowledge(name)
+
+				var zero T
+
+				slot.data, slot.ok = zero, false
+			}
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-stop:
+			close(proxy)
+
+			return
+
+		case <-abort:
+			close(proxy)
+
+			return
+
+		case data, ok := <-source:
+			if !ok {
+				close(proxy)
+
+				return
+			}
+
+			c.signalRead(name)
+			c.observeChannel(name, source, false)
+
+			slot.data, slot.ok = data, true
+		}
+	}
+}
+
+func (c *Conveyer[T]) forwardOutput(
+	ctx context.Context,
+	abort <-chan struct{},
+	handlerDone <-chan struct{},
+	name string,
+	proxy chan T,
+	target chan T,
+	inFlight *atomic.Int64,
+	observeLatency func(),
+) bool {
+	defer inFlight.Store(0)
+
+	for {
+		c.waitForRoom(ctx, name, target)
+		inFlight.Store(1)
+
+		select {
+		case <-abort:
+			return c.absorbOutput(handlerDone, name, proxy)
+
+		case <-handlerDone:
+			return proxyClosed(proxy)
+
+		case data, ok := <-proxy:
+			if !ok {
+				return true
+			}
+
+			observeLatency()
+
+			if err := c.persist(name, data, func() error {
+				return c.sendBlocking(ctx, name, target, data)
+			}); err != nil {
+				c.recordLost(name, data)
+			} else {
+				c.observeChannel(name, target, true)
+			}
+
+			inFlight.Store(0)
+		}
+	}
+}
+
+func (c *Conveyer[T]) absorbOutput(handlerDone <-chan struct{}, name string, proxy chan T) bool {
+	for {
+		select {
+		case <-handlerDone:
+			return proxyClosed(proxy)
+
+		case data, ok := <-proxy:
+			if !ok {
+				return true
+			}
+
+			c.recordLost(name, data)
+		}
+	}
+}
+
+func proxyClosed[T any](proxy chan T) bool {
+	select {
+	case _, ok := <-proxy:
+		return !ok
+	default:
+	}
+
+	return false
+}
+
+func (c *Conveyer[T]) recordLost(name string, data T) {
+	c.mutex.Lock()
+	c.lost[name] = append(c.lost[name], data)
+	c.mutex.Unlock()
+
+	c.emit(newEvent(EventMessageDropped, "", name, 0, nil))
+}
+
+func (c *Conveyer[T]) recordPending(names []string, pending []pendingSlot[T]) {
+	for index, slot := range pending {
+		if slot.ok {
+			c.recordLost(names[index], slot.data)
+		}
+	}
+}
+
+func (c *Conveyer[T]) closeOutputs(handler string, names []string, outputs []chan T, closed []bool) {
+	for index, isClosed := range closed {
+		if !isClosed || !c.markClosed(names[index]) {
+			continue
+		}
+
+		close(outputs[index])
+		c.emit(newEvent(EventChannelClosed, handler, names[index], 0, nil))
+	}
+}
+
+func (c *Conveyer[T]) markClosed(name string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closedChannels[name] {
+		return false
+	}
+
+	c.closedChannels[name] = true
+
+	return true
+}
@@ -0,0 +1,48 @@
+package conveyer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_NonBlocking(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	require.NoError(t, pipeline.TrySend("in", "a"))
+	require.ErrorIs(t, pipeline.TrySend("in", "b"), conveyer.ErrChanFull)
+
+	_, err := pipeline.TryRecv("out")
+	require.ErrorIs(t, err, conveyer.ErrNoDataAvailable)
+
+	data, err := pipeline.TryRecv("in")
+	require.NoError(t, err)
+	require.Equal(t, "a", data)
+}
+
+func TestConveyer_Deadlines(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, pipeline.SendContext(ctx, "in", "a"))
+	require.ErrorIs(t, pipeline.SendContext(ctx, "in", "b"), context.DeadlineExceeded)
+
+	_, err := pipeline.RecvContext(ctx, "out")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = pipeline.RecvContext(context.Background(), "missing")
+	require.Error(t, err)
+}
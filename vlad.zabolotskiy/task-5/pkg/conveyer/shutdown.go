@@ -0,0 +1,165 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const abortGracePeriod = 100 * time.Millisecond
+
+var (
+	ErrShutdown   = errors.New("conveyer is shut down")
+	ErrNotRunning = errors.New("conveyer is not running")
+)
+
+type ShutdownReport[T any] struct {
+	Lost map[string][]T
+}
+
+func (r ShutdownReport[T]) LostCount() int {
+	count := 0
+
+	for _, messages := range r.Lost {
+		count += len(messages)
+	}
+
+	return count
+}
+
+type shutdownControl struct {
+	cancel context.CancelFunc
+	abort  chan struct{}
+	done   chan struct{}
+}
+
+func (c *Conveyer[T]) Shutdown(ctx context.Context) (ShutdownReport[T], error) {
+	report := ShutdownReport[T]{Lost: make(map[string][]T)}
+
+	c.mutex.Lock()
+
+	if !c.isRunning {
+		c.mutex.Unlock()
+
+		return report, ErrNotRunning
+	}
+
+	if c.isShutdown {
+		c.mutex.Unlock()
+
+		return report, ErrShutdown
+	}
+
+	c.isShutdown = true
+	topo := newTopology(c.specs(), c.declaredInputs, c.declaredOutputs)
+	done := c.done
+	cancel := c.cancelFunc
+	abort := c.abort
+	c.mutex.Unlock()
+
+	c.emit(newEvent(EventShutdownBegun, "", "", 0, nil))
+
+	report, err := c.shutdown(ctx, topo, shutdownControl{cancel: cancel, abort: abort, done: done})
+	c.emit(newEvent(EventShutdownCompleted, "", "", 0, err))
+
+	return report, err
+}
+
+func (c *Conveyer[T]) shutdown(ctx context.Context, topo topology, control shutdownControl) (ShutdownReport[T], error) {
+	if err := c.closeInputs(ctx, topo); err != nil {
+		return c.abortShutdown(topo, control, err)
+	}
+
+	select {
+	case <-control.done:
+		return c.collectLost(topo), nil
+	case <-ctx.Done():
+		return c.abortShutdown(topo, control, ctx.Err())
+	}
+}
+
+func (c *Conveyer[T]) closeInputs(ctx context.Context, topo topology) error {
+	sendsFinished := make(chan struct{})
+
+	go func() {
+		c.sending.Wait()
+		close(sendsFinished)
+	}()
+
+	select {
+	case <-sendsFinished:
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+
+	for _, name := range topo.channelNames() {
+		if len(topo.writers[name]) == 0 && c.markClosed(name) {
+			close(c.getChannel(name))
+			c.emit(newEvent(EventChannelClosed, "", name, 0, nil))
+		}
+	}
+
+	return nil
+}
+
+func (c *Conveyer[T]) abortShutdown(topo topology, control shutdownControl, cause error) (ShutdownReport[T], error) {
+	close(control.abort)
+
+	timer := time.NewTimer(abortGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-control.done:
+	case <-timer.C:
+		control.cancel()
+		<-control.done
+	}
+
+	return c.collectLost(topo), fmt.Errorf("shutting down: %w", cause)
+}
+
+func (c *Conveyer[T]) collectLost(topo topology) ShutdownReport[T] {
+	report := ShutdownReport[T]{Lost: make(map[string][]T)}
+
+	c.mutex.Lock()
+	for name, lost := range c.lost {
+		report.Lost[name] = append(report.Lost[name], lost...)
+	}
+
+	c.lost = make(map[string][]T)
+	c.mutex.Unlock()
+
+	for _, name := range topo.channelNames() {
+		if len(topo.readers[name]) == 0 {
+			continue
+		}
+
+		if lost := drainChannel(c.getChannel(name)); len(lost) > 0 {
+			report.Lost[name] = append(report.Lost[name], lost...)
+
+			for range lost {
+				c.emit(newEvent(EventMessageDropped, "", name, 0, nil))
+			}
+		}
+	}
+
+	return report
+}
+
+func drainChannel[T any](channel chan T) []T {
+	var drained []T
+
+	for {
+		select {
+		case data, ok := <-channel:
+			if !ok {
+				return drained
+			}
+
+			drained = append(drained, data)
+		default:
+			return drained
+		}
+	}
+}
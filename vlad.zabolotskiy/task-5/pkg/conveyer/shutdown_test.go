@@ -0,0 +1,184 @@
+package conveyer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drains", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "decorated")
+		pipeline.RegisterSeparator(handlers.SeparatorFunc, "decorated", []string{"left", "right"})
+		pipeline.RegisterMultiplexer(handlers.MultiplexerFunc, []string{"left", "right"}, "out")
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		require.Eventually(t, func() bool {
+			_, err := pipeline.Shutdown(context.Background())
+
+			return err == nil
+		}, time.Second, time.Millisecond)
+
+		require.ErrorIs(t, pipeline.Send("in", "late"), conveyer.ErrShutdown)
+		require.NoError(t, harness.Wait())
+
+		_, err := pipeline.Recv("out")
+		require.ErrorIs(t, err, conveyer.ErrClosed)
+	})
+
+	t.Run("flushes buffered data", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		for _, data := range []string{"a", "b", "c"} {
+			require.NoError(t, pipeline.Send("in", data))
+		}
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		var report conveyer.ShutdownReport[string]
+
+		require.Eventually(t, func() bool {
+			var err error
+
+			report, err = pipeline.Shutdown(context.Background())
+
+			return err == nil
+		}, time.Second, time.Millisecond)
+
+		require.Zero(t, report.LostCount())
+
+		var received []string
+
+		for data, err := range pipeline.Stream("out") {
+			require.NoError(t, err)
+
+			received = append(received, data)
+		}
+
+		require.Equal(t, []string{"decorated: a", "decorated: b", "decorated: c"}, received)
+	})
+
+	t.Run("deadline reports lost messages", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		for _, data := range []string{"a", "b", "c"} {
+			require.NoError(t, pipeline.Send("in", data))
+		}
+
+		require.Eventually(t, func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			report, err := pipeline.Shutdown(ctx)
+			if err == nil {
+				return false
+			}
+
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			require.Equal(t, []string{"c"}, report.Lost["in"])
+
+			return true
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("deadline reports in-flight handler data", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		for _, data := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, pipeline.Send("in", data))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		report, err := pipeline.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, []string{"c", "d"}, report.Lost["in"])
+		require.Equal(t, []string{"decorated: b"}, report.Lost["out"])
+	})
+
+	t.Run("reports messages left by a failed handler", func(t *testing.T) {
+		t.Parallel()
+
+		received := make(chan string, 1)
+		release := make(chan struct{})
+		errBoom := errors.New("boom")
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(func(_ context.Context, input chan string, output chan string) error {
+			defer close(output)
+
+			received <- <-input
+			<-release
+
+			return errBoom
+		}, "in", "out")
+
+		events, unsubscribe := pipeline.Subscribe(16)
+		defer unsubscribe()
+
+		for _, data := range []string{"x", "y", "z"} {
+			require.NoError(t, pipeline.Send("in", data))
+		}
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		require.Equal(t, "x", <-received)
+
+		type result struct {
+			report conveyer.ShutdownReport[string]
+			err    error
+		}
+
+		shutdown := make(chan result, 1)
+
+		go func() {
+			report, err := pipeline.Shutdown(context.Background())
+			shutdown <- result{report: report, err: err}
+		}()
+
+		for event := range events {
+			if event.Kind == conveyer.EventChannelClosed && event.Channel == "in" {
+				break
+			}
+		}
+
+		close(release)
+
+		outcome := <-shutdown
+		require.NoError(t, outcome.err)
+		require.Equal(t, []string{"y", "z"}, outcome.report.Lost["in"])
+		require.ErrorIs(t, harness.Wait(), errBoom)
+	})
+}
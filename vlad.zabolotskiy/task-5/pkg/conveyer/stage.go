@@ -0,0 +1,240 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const stageSeparator = "/"
+
+var (
+	ErrInvalidPrefix = errors.New("mount prefix is invalid")
+	ErrPrefixInUse   = errors.New("mount prefix is already in use")
+	ErrUnknownPort   = errors.New("stage has no such port")
+)
+
+type stageHandler[T any] struct {
+	kind    HandlerKind
+	inputs  []string
+	outputs []string
+	options []HandlerOption
+	run     func(ctx context.Context, inputs []chan T, outputs []chan T) error
+}
+
+type Stage[T any] struct {
+	inputs          []string
+	outputs         []string
+	handlers        []stageHandler[T]
+	declaredInputs  []string
+	declaredOutputs []string
+}
+
+type mountedStage[T any] struct {
+	handlers []stageHandler[T]
+	inputs   []string
+	outputs  []string
+}
+
+func NewStage[T any](inputs []string, outputs []string) *Stage[T] {
+	return &Stage[T]{
+		inputs:          append([]string(nil), inputs...),
+		outputs:         append([]string(nil), outputs...),
+		handlers:        nil,
+		declaredInputs:  nil,
+		declaredOutputs: nil,
+	}
+}
+
+func (s *Stage[T]) Inputs() []string {
+	return append([]string(nil), s.inputs...)
+}
+
+func (s *Stage[T]) Outputs() []string {
+	return append([]string(nil), s.outputs...)
+}
+
+func (s *Stage[T]) register(
+	kind HandlerKind,
+	inputs []string,
+	outputs []string,
+	options []HandlerOption,
+	run func(ctx context.Context, inputs []chan T, outputs []chan T) error,
+) {
+	s.handlers = append(s.handlers, stageHandler[T]{
+		kind:    kind,
+		inputs:  append([]string(nil), inputs...),
+		outputs: append([]string(nil), outputs...),
+		options: append([]HandlerOption(nil), options...),
+		run:     run,
+	})
+}
+
+func (s *Stage[T]) RegisterDecorator(
+	decoratorFunction DecoratorFunc[T],
+	input string,
+	output string,
+	options ...HandlerOption,
+) {
+	s.register(KindDecorator, []string{input}, []string{output}, options,
+		func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+			return decoratorFunction(ctx, inputs[0], outputs[0])
+		})
+}
+
+func (s *Stage[T]) RegisterMultiplexer(
+	multiplexerFunction MultiplexerFunc[T],
+	inputs []string,
+	output string,
+	options ...HandlerOption,
+) {
+	s.register(KindMultiplexer, inputs, []string{output}, options,
+		func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+			return multiplexerFunction(ctx, inputs, outputs[0])
+		})
+}
+
+func (s *Stage[T]) RegisterSeparator(
+	separatorFunction SeparatorFunc[T],
+	input string,
+	outputs []string,
+	options ...HandlerOption,
+) {
+	s.register(KindSeparator, []string{input}, outputs, options,
+		func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+			return separatorFunction(ctx, inputs[0], outputs)
+		})
+}
+
+func (s *Stage[T]) channelNames() []string {
+	names := append(s.Inputs(), s.outputs...)
+	names = append(names, s.declaredInputs...)
+	names = append(names, s.declaredOutputs...)
+
+	for _, registered := range s.handlers {
+		names = append(names, registered.inputs...)
+		names = append(names, registered.outputs...)
+	}
+
+	return names
+}
+
+func (s *Stage[T]) Mount(prefix string, stage *Stage[T], ports map[string]string) error {
+	mounted, err := stage.namespaced(prefix, ports)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPrefix(prefix, s.channelNames()); err != nil {
+		return err
+	}
+
+	s.handlers = append(s.handlers, mounted.handlers...)
+	s.declaredInputs = append(s.declaredInputs, mounted.inputs...)
+	s.declaredOutputs = append(s.declaredOutputs, mounted.outputs...)
+
+	return nil
+}
+
+func (c *Conveyer[T]) Mount(prefix string, stage *Stage[T], ports map[string]string) error {
+	mounted, err := stage.namespaced(prefix, ports)
+	if err != nil {
+		return err
+	}
+
+	if err := c.install(prefix, mounted.handlers, mounted.inputs, mounted.outputs); err != nil {
+		return fmt.Errorf("mounting %q: %w", prefix, err)
+	}
+
+	return nil
+}
+
+func namespace(prefix string, ports map[string]string) func(name string) string {
+	return func(name string) string {
+		if bound, exists := ports[name]; exists {
+			return bound
+		}
+
+		return prefix + stageSeparator + name
+	}
+}
+
+func checkPrefix(prefix string, existing []string) error {
+	for _, name := range existing {
+		if strings.HasPrefix(name, prefix+stageSeparator) {
+			return fmt.Errorf("mounting %q: %w", prefix, ErrPrefixInUse)
+		}
+	}
+
+	return nil
+}
+
+func (s *Stage[T]) namespaced(prefix string, ports map[string]string) (mountedStage[T], error) {
+	if prefix == "" || strings.Contains(prefix, stageSeparator) {
+		return mountedStage[T]{}, fmt.Errorf("mounting %q: %w", prefix, ErrInvalidPrefix)
+	}
+
+	for port := range ports {
+		if !slices.Contains(s.inputs, port) && !slices.Contains(s.outputs, port) {
+			return mountedStage[T]{}, fmt.Errorf("mounting %q: port %q: %w", prefix, port, ErrUnknownPort)
+		}
+	}
+
+	rename := namespace(prefix, ports)
+	mounted := mountedStage[T]{
+		handlers: make([]stageHandler[T], 0, len(s.handlers)),
+		inputs:   renameAll(rename, s.declaredInputs),
+		outputs:  renameAll(rename, s.declaredOutputs),
+	}
+
+	for _, port := range s.inputs {
+		if _, bound := ports[port]; !bound {
+			mounted.inputs = append(mounted.inputs, rename(port))
+		}
+	}
+
+	for _, port := range s.outputs {
+		if _, bound := ports[port]; !bound {
+			mounted.outputs = append(mounted.outputs, rename(port))
+		}
+	}
+
+	for _, registered := range s.handlers {
+		mounted.handlers = append(mounted.handlers, stageHandler[T]{
+			kind:    registered.kind,
+			inputs:  renameAll(rename, registered.inputs),
+			outputs: renameAll(rename, registered.outputs),
+			options: append(slices.Clone(registered.options), withNamespace(prefix, rename)),
+			run:     registered.run,
+		})
+	}
+
+	return mounted, nil
+}
+
+func renameAll(rename func(name string) string, names []string) []string {
+	renamed := make([]string, len(names))
+	for index, name := range names {
+		renamed[index] = rename(name)
+	}
+
+	return renamed
+}
+
+func withNamespace(prefix string, rename func(name string) string) HandlerOption {
+	return func(options *handlerOptions) {
+		sizes := make(map[string]int, len(options.channelSizes))
+
+		for name, size := range options.channelSizes {
+			sizes[rename(name)] = size
+		}
+
+		options.channelSizes = sizes
+
+		if options.stateName != "" {
+			options.stateName = prefix + stageSeparator + options.stateName
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package conveyer_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fanStage() *conveyer.Stage[string] {
+	stage := conveyer.NewStage[string]([]string{"in"}, []string{"out"})
+	stage.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "decorated")
+	stage.RegisterSeparator(handlers.SeparatorFunc, "decorated", []string{"left", "right"})
+	stage.RegisterMultiplexer(handlers.MultiplexerFunc, []string{"left", "right"}, "out")
+
+	return stage
+}
+
+func TestConveyer_MountStages(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.DeclareInputs("in")
+	require.NoError(t, pipeline.Mount("first", fanStage(), map[string]string{"in": "in", "out": "middle"}))
+	require.NoError(t, pipeline.Mount("second", fanStage(), map[string]string{"in": "middle"}))
+	require.NoError(t, pipeline.Validate())
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for _, data := range []string{"a", "b", "c"} {
+		require.NoError(t, pipeline.Send("in", data))
+	}
+
+	received := make([]string, 0, 3)
+
+	for range 3 {
+		data, err := pipeline.Recv("second/out")
+		require.NoError(t, err)
+
+		received = append(received, data)
+	}
+
+	sort.Strings(received)
+	require.Equal(t, []string{"decorated: a", "decorated: b", "decorated: c"}, received)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestStage_Nested(t *testing.T) {
+	t.Parallel()
+
+	outer := conveyer.NewStage[string]([]string{"in"}, []string{"out"})
+	require.NoError(t, outer.Mount("inner", fanStage(), map[string]string{"in": "in", "out": "out"}))
+
+	pipeline := conveyer.New(4)
+	require.NoError(t, pipeline.Mount("outer", outer, nil))
+	require.NoError(t, pipeline.Validate())
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("outer/in", "a"))
+
+	data, err := pipeline.Recv("outer/out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	status := pipeline.Status()
+	require.Len(t, status.Handlers, 3)
+	require.Equal(t, []string{"outer/inner/decorated"}, status.Handlers[0].Outputs)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestConveyer_MountErrors(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+
+	require.ErrorIs(t, pipeline.Mount("", fanStage(), nil), conveyer.ErrInvalidPrefix)
+	require.ErrorIs(t, pipeline.Mount("a/b", fanStage(), nil), conveyer.ErrInvalidPrefix)
+	require.ErrorIs(t, pipeline.Mount("stage", fanStage(), map[string]string{"decorated": "x"}), conveyer.ErrUnknownPort)
+
+	require.NoError(t, pipeline.Mount("stage", fanStage(), nil))
+	require.ErrorIs(t, pipeline.Mount("stage", fanStage(), nil), conveyer.ErrPrefixInUse)
+}
+
+func TestConveyer_MountWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.DeclareInputs("in")
+	pipeline.DeclareOutputs("out")
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+
+	err := pipeline.Mount("bad", fanStage(), map[string]string{"out": "out"})
+	require.ErrorIs(t, err, conveyer.ErrMultipleWriters)
+	require.Len(t, pipeline.Status().Handlers, 1)
+	require.ErrorIs(t, pipeline.Send("bad/in", "a"), conveyer.ErrChanNotFound)
+
+	require.NoError(t, pipeline.Mount("bad", fanStage(), nil))
+	require.NoError(t, pipeline.Send("bad/in", "a"))
+
+	data, err := pipeline.Recv("bad/out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+	require.Len(t, pipeline.Status().Handlers, 4)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestStage_NestedUnboundPorts(t *testing.T) {
+	t.Parallel()
+
+	outer := conveyer.NewStage[string]([]string{"in"}, nil)
+	require.NoError(t, outer.Mount("inner", fanStage(), map[string]string{"in": "in"}))
+
+	pipeline := conveyer.New(4)
+	pipeline.DeclareInputs("in")
+	pipeline.DeclareOutputs("out")
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+
+	require.NoError(t, pipeline.Mount("outer", outer, nil))
+	require.NoError(t, pipeline.Send("outer/in", "a"))
+
+	data, err := pipeline.Recv("outer/inner/out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	require.NoError(t, harness.Stop())
+}
@@ -0,0 +1,305 @@
+package conveyer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	checkpointVersion     = 1
+	checkpointPermissions = 0o644
+)
+
+var ErrCheckpointVersion = errors.New("unsupported checkpoint version")
+
+type State struct {
+	mutex  sync.Mutex
+	values map[string]json.RawMessage
+	dirty  bool
+}
+
+type stateKey struct{}
+
+type checkpointFile struct {
+	Version  int                                   `json:"version"`
+	Handlers map[string]map[string]json.RawMessage `json:"handlers"`
+}
+
+func newState(values map[string]json.RawMessage) *State {
+	if values == nil {
+		values = make(map[string]json.RawMessage)
+	}
+
+	return &State{mutex: sync.Mutex{}, values: values, dirty: false}
+}
+
+func StateFrom(ctx context.Context) *State {
+	state, ok := ctx.Value(stateKey{}).(*State)
+	if !ok {
+		return newState(nil)
+	}
+
+	return state
+}
+
+func (s *State) Get(key string, target any) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value, exists := s.values[key]
+	if !exists {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(value, target); err != nil {
+		return true, fmt.Errorf("decoding state %q: %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (s *State) Set(key string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding state %q: %w", key, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[key] = encoded
+	s.dirty = true
+
+	return nil
+}
+
+func (s *State) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.values[key]; exists {
+		delete(s.values, key)
+		s.dirty = true
+	}
+}
+
+func (s *State) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make([]string, 0, len(s.values))
+
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (s *State) snapshot() (map[string]json.RawMessage, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values := make(map[string]json.RawMessage, len(s.values))
+
+	for key, value := range s.values {
+		values[key] = value
+	}
+
+	dirty := s.dirty
+	s.dirty = false
+
+	return values, dirty
+}
+
+func (s *State) markDirty() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.dirty = true
+}
+
+func WithStateName(name string) HandlerOption {
+	return func(options *handlerOptions) {
+		options.stateName = name
+	}
+}
+
+func (c *Conveyer[T]) SetCheckpoint(path string, interval time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkpointPath = path
+	c.checkpointEvery = interval
+}
+
+func (c *Conveyer[T]) withState(ctx context.Context, registered handler[T]) context.Context {
+	name := registered.options.stateName
+	if name == "" {
+		name = registered.name
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state, exists := c.handlerStates[name]
+	if !exists {
+		state = newState(nil)
+		c.handlerStates[name] = state
+	}
+
+	return context.WithValue(ctx, stateKey{}, state)
+}
+
+func (c *Conveyer[T]) restoreCheckpoint() error {
+	path := c.checkpointPath
+
+	if path == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("reading checkpoint: %w", err)
+	}
+
+	var checkpoint checkpointFile
+
+	if err := json.Unmarshal(content, &checkpoint); err != nil {
+		return fmt.Errorf("decoding checkpoint: %w", err)
+	}
+
+	if checkpoint.Version != checkpointVersion {
+		return fmt.Errorf("%w: %d", ErrCheckpointVersion, checkpoint.Version)
+	}
+
+	for name, values := range checkpoint.Handlers {
+		c.handlerStates[name] = newState(values)
+	}
+
+	return nil
+}
+
+func (c *Conveyer[T]) Checkpoint() error {
+	c.mutex.RLock()
+	path := c.checkpointPath
+	states := make(map[string]*State, len(c.handlerStates))
+
+	for name, state := range c.handlerStates {
+		states[name] = state
+	}
+	c.mutex.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+
+	checkpoint := checkpointFile{
+		Version:  checkpointVersion,
+		Handlers: make(map[string]map[string]json.RawMessage, len(states)),
+	}
+	dirty := false
+
+	for name, state := range states {
+		values, changed := state.snapshot()
+		checkpoint.Handlers[name] = values
+		dirty = dirty || changed
+	}
+
+	if _, err := os.Stat(path); err == nil && !dirty {
+		return nil
+	}
+
+	content, err := json.Marshal(checkpoint)
+	if err == nil {
+		err = writeFileAtomic(path, content)
+	}
+
+	if err != nil {
+		for _, state := range states {
+			state.markDirty()
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Conveyer[T]) checkpointOrReport() {
+	if err := c.Checkpoint(); err != nil {
+		c.emit(newEvent(EventCheckpointFailed, "", "", 0, err))
+	}
+}
+
+func (c *Conveyer[T]) runCheckpoints(stop <-chan struct{}) {
+	c.mutex.RLock()
+	interval := c.checkpointEvery
+	c.mutex.RUnlock()
+
+	if interval <= 0 {
+		<-stop
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.checkpointOrReport()
+		}
+	}
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	temporary, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+
+	defer os.Remove(temporary.Name())
+
+	if _, err := temporary.Write(content); err != nil {
+		temporary.Close()
+
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+
+	if err := temporary.Sync(); err != nil {
+		temporary.Close()
+
+		return fmt.Errorf("syncing checkpoint: %w", err)
+	}
+
+	if err := temporary.Close(); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+
+	if err := os.Chmod(temporary.Name(), checkpointPermissions); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+
+	if err := os.Rename(temporary.Name(), path); err != nil {
+		return fmt.Errorf("replacing checkpoint: %w", err)
+	}
+
+	return nil
+}
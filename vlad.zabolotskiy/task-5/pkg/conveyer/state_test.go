@@ -0,0 +1,187 @@
+package conveyer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func dedupeCounter(ctx context.Context, input chan string, output chan string) error {
+	defer close(output)
+
+	state := conveyer.StateFrom(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case data, ok := <-input:
+			if !ok {
+				return nil
+			}
+
+			if seen, err := state.Get("seen:"+data, new(bool)); err != nil || seen {
+				continue
+			}
+
+			count := 0
+			if _, err := state.Get("count", &count); err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			count++
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case output <- strconv.Itoa(count) + ":" + data:
+			}
+
+			if err := state.Set("count", count); err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			if err := state.Set("seen:"+data, true); err != nil {
+				return err //nolint:wrapcheck
+			}
+		}
+	}
+}
+
+func runStateful(t *testing.T, path string, send []string, expected []string) {
+	t.Helper()
+
+	pipeline := conveyer.New(4)
+	pipeline.SetCheckpoint(path, time.Hour)
+	pipeline.RegisterDecorator(dedupeCounter, "in", "out", conveyer.WithStateName("dedupe"))
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for _, data := range send {
+		require.NoError(t, pipeline.Send("in", data))
+	}
+
+	for _, want := range expected {
+		data, err := pipeline.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, want, data)
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := pipeline.Shutdown(context.Background())
+
+		return err == nil
+	}, time.Second, time.Millisecond)
+	require.NoError(t, harness.Wait())
+
+	_, err := pipeline.Recv("out")
+	require.ErrorIs(t, err, conveyer.ErrClosed)
+}
+
+func TestConveyer_StateCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	runStateful(t, path, []string{"a", "b", "a"}, []string{"1:a", "2:b"})
+	runStateful(t, path, []string{"c", "b", "d"}, []string{"3:c", "4:d"})
+}
+
+func TestConveyer_StatePeriodicCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	pipeline := conveyer.New(4)
+	pipeline.SetCheckpoint(path, 5*time.Millisecond)
+	pipeline.RegisterSeparator(handlers.SeparatorFunc, "in", []string{"left", "right"})
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	data, err := pipeline.Recv("left")
+	require.NoError(t, err)
+	require.Equal(t, "a", data)
+
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+
+		return err == nil && string(content) == `{"version":1,"handlers":{"separator[0]":{"counter":1}}}`
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, harness.Stop())
+
+	restarted := conveyer.New(4)
+	restarted.SetCheckpoint(path, 0)
+	restarted.RegisterSeparator(handlers.SeparatorFunc, "in", []string{"left", "right"})
+
+	restartedHarness := conveyertest.New(t, restarted)
+	restartedHarness.Start()
+
+	require.NoError(t, restarted.Send("in", "b"))
+
+	data, err = restarted.Recv("right")
+	require.NoError(t, err)
+	require.Equal(t, "b", data)
+
+	require.NoError(t, restartedHarness.Stop())
+}
+
+func TestConveyer_StateRejectsUnknownVersion(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"handlers":{}}`), 0o600))
+
+	pipeline := conveyer.New(1)
+	pipeline.SetCheckpoint(path, time.Second)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	require.ErrorIs(t, pipeline.Run(context.Background()), conveyer.ErrCheckpointVersion)
+}
+
+func TestConveyer_CheckpointFailureIsReported(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.SetCheckpoint(filepath.Join(t.TempDir(), "missing", "checkpoint.json"), 0)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+
+	require.NoError(t, harness.Stop())
+
+	var failures []error
+
+	for len(events) > 0 {
+		if event := <-events; event.Kind == conveyer.EventCheckpointFailed {
+			failures = append(failures, event.Err)
+		}
+	}
+
+	require.Len(t, failures, 1)
+	require.ErrorContains(t, failures[0], "checkpoint")
+}
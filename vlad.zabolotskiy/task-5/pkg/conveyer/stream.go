@@ -0,0 +1,26 @@
+package conveyer
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+func (c *Conveyer[T]) Stream(name string) iter.Seq2[T, error] {
+	return c.StreamContext(context.Background(), name)
+}
+
+func (c *Conveyer[T]) StreamContext(ctx context.Context, name string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			data, err := c.RecvContext(ctx, name)
+			if errors.Is(err, ErrClosed) {
+				return
+			}
+
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package conveyer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Stream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ends when channel is closed", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		events, unsubscribe := pipeline.Subscribe(16)
+		defer unsubscribe()
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		for event := range events {
+			if event.Kind == conveyer.EventHandlerStarted {
+				break
+			}
+		}
+
+		require.NoError(t, pipeline.Send("in", "a"))
+		require.NoError(t, pipeline.Send("in", "undefined"))
+
+		shutdown := make(chan error, 1)
+
+		go func() {
+			_, err := pipeline.Shutdown(context.Background())
+			shutdown <- err
+		}()
+
+		var received []string
+
+		for data, err := range pipeline.Stream("out") {
+			require.NoError(t, err)
+
+			received = append(received, data)
+		}
+
+		require.NoError(t, <-shutdown)
+		require.Equal(t, []string{"decorated: a", "decorated: undefined"}, received)
+
+		_, err := pipeline.TryRecv("out")
+		require.ErrorIs(t, err, conveyer.ErrClosed)
+	})
+
+	t.Run("stops on break and context", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		for _, data := range []string{"a", "b", "c"} {
+			require.NoError(t, pipeline.Send("in", data))
+		}
+
+		for data, err := range pipeline.Stream("in") {
+			require.NoError(t, err)
+			require.Equal(t, "a", data)
+
+			break
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var (
+			received []string
+			errs     []error
+		)
+
+		for data, err := range pipeline.StreamContext(ctx, "in") {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			received = append(received, data)
+		}
+
+		require.Equal(t, []string{"b", "c"}, received)
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], context.DeadlineExceeded)
+	})
+
+	t.Run("reports a missing channel", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+
+		count := 0
+
+		for _, err := range pipeline.Stream("missing") {
+			require.ErrorIs(t, err, conveyer.ErrChanNotFound)
+
+			count++
+		}
+
+		require.Equal(t, 1, count)
+	})
+}
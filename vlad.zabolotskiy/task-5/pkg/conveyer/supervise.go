@@ -0,0 +1,210 @@
+package conveyer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	unlimitedRestarts     = -1
+)
+
+type handlerOptions struct {
+	channelSizes   map[string]int
+	stateName      string
+	restart        bool
+	isolate        bool
+	maxRestarts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+type HandlerOption func(options *handlerOptions)
+
+func newHandlerOptions(options []HandlerOption) handlerOptions {
+	result := handlerOptions{
+		channelSizes:   make(map[string]int),
+		stateName:      "",
+		restart:        false,
+		isolate:        false,
+		maxRestarts:    unlimitedRestarts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+	}
+
+	for _, option := range options {
+		option(&result)
+	}
+
+	return result
+}
+
+func WithRestart(initialBackoff time.Duration, maxBackoff time.Duration) HandlerOption {
+	return func(options *handlerOptions) {
+		options.restart = true
+
+		if initialBackoff > 0 {
+			options.initialBackoff = initialBackoff
+		}
+
+		if maxBackoff > 0 {
+			options.maxBackoff = maxBackoff
+		}
+	}
+}
+
+func WithMaxRestarts(count int) HandlerOption {
+	return func(options *handlerOptions) {
+		options.restart = true
+		options.maxRestarts = max(count, 0)
+	}
+}
+
+func WithIsolation() HandlerOption {
+	return func(options *handlerOptions) {
+		options.isolate = true
+	}
+}
+
+func (c *Conveyer[T]) runHandler(ctx context.Context, registered handler[T], state *runningHandler) error {
+	name := registered.name
+	ctx = c.withState(c.withRejecter(ctx, name), registered)
+	inputs := c.lookupChannels(registered.inputs)
+	outputs := c.lookupChannels(registered.outputs)
+
+	c.mutex.RLock()
+	sink := c.metrics
+	abort := c.abort
+	c.mutex.RUnlock()
+
+	pending := make([]pendingSlot[T], len(inputs))
+	backoff := registered.options.initialBackoff
+
+	for restarts := 0; ; restarts++ {
+		c.setHandlerState(name, HandlerRunning, restarts, nil)
+		c.emit(newEvent(EventHandlerStarted, name, "", restarts, nil))
+
+		closed, err := c.runProxied(ctx, sink, registered, inputs, outputs, pending, state.stopInputs, abort)
+
+		c.emit(newEvent(EventHandlerExited, name, "", restarts, err))
+
+		if state.removing.Load() {
+			c.setHandlerState(name, HandlerStopped, restarts, err)
+			c.closeOutputs(name, registered.outputs, outputs, keepReadOutputs(closed, state.keepOpen))
+			c.rejectPending(state.runCtx, name, pending)
+
+			return nil
+		}
+
+		if err != nil && sink != nil {
+			sink.HandlerError(name, err)
+		}
+
+		if !c.shouldRestart(ctx, abort, registered.options, restarts, err) {
+			c.setHandlerState(name, finalState(err), restarts, err)
+			c.closeOutputs(name, registered.outputs, outputs, closed)
+			c.recordPending(registered.inputs, pending)
+
+			return c.settle(name, registered.options, err)
+		}
+
+		c.setHandlerState(name, HandlerRestarting, restarts+1, err)
+		c.emit(newEvent(EventHandlerRestarting, name, "", restarts+1, err))
+
+		if !sleepContext(ctx, abort, backoff) {
+			if state.removing.Load() {
+				c.setHandlerState(name, HandlerStopped, restarts, err)
+				c.closeOutputs(name, registered.outputs, outputs, keepReadOutputs(closed, state.keepOpen))
+				c.rejectPending(state.runCtx, name, pending)
+
+				return nil
+			}
+
+			c.setHandlerState(name, finalState(err), restarts, err)
+			c.closeOutputs(name, registered.outputs, outputs, closed)
+			c.recordPending(registered.inputs, pending)
+
+			return c.settle(name, registered.options, err)
+		}
+
+		backoff = min(backoff*2, registered.options.maxBackoff)
+	}
+}
+
+func keepReadOutputs(closed []bool, keepOpen []bool) []bool {
+	closing := make([]bool, len(closed))
+
+	for index, isClosed := range closed {
+		closing[index] = isClosed && (index >= len(keepOpen) || !keepOpen[index])
+	}
+
+	return closing
+}
+
+func finalState(err error) HandlerState {
+	if err != nil {
+		return HandlerFailed
+	}
+
+	return HandlerStopped
+}
+
+func (c *Conveyer[T]) shouldRestart(
+	ctx context.Context,
+	abort <-chan struct{},
+	options handlerOptions,
+	restarts int,
+	err error,
+) bool {
+	if err == nil || !options.restart || ctx.Err() != nil || isClosed(abort) {
+		return false
+	}
+
+	return options.maxRestarts == unlimitedRestarts || restarts < options.maxRestarts
+}
+
+func (c *Conveyer[T]) settle(name string, options handlerOptions, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("%s: %w", name, err)
+
+	if !options.isolate {
+		return err
+	}
+
+	c.emit(newEvent(EventHandlerIsolated, name, "", 0, err))
+
+	c.mutex.Lock()
+	c.isolated = append(c.isolated, err)
+	c.mutex.Unlock()
+
+	return nil
+}
+
+func sleepContext(ctx context.Context, abort <-chan struct{}, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-abort:
+		return false
+	}
+}
+
+func isClosed(signal <-chan struct{}) bool {
+	select {
+	case <-signal:
+		return true
+	default:
+		return false
+	}
+}
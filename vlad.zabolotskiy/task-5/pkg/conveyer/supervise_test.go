@@ -0,0 +1,134 @@
+package conveyer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Supervision(t *testing.T) {
+	t.Parallel()
+
+	t.Run("restart", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(2)
+		pipeline.RegisterDecorator(
+			handlers.PrefixDecoratorFunc, "in", "out",
+			conveyer.WithRestart(time.Millisecond, 10*time.Millisecond),
+		)
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		require.NoError(t, pipeline.Send("in", "no decorator"))
+		require.NoError(t, pipeline.Send("in", "a"))
+
+		data, err := pipeline.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, "decorated: a", data)
+
+		require.NoError(t, harness.Stop())
+	})
+
+	t.Run("max restarts", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(
+			handlers.PrefixDecoratorFunc, "in", "out",
+			conveyer.WithRestart(time.Millisecond, time.Millisecond),
+			conveyer.WithMaxRestarts(1),
+		)
+
+		for range 2 {
+			require.NoError(t, pipeline.Send("in", "no decorator"))
+		}
+
+		err := pipeline.Run(context.Background())
+		require.ErrorIs(t, err, handlers.ErrCantBeDecorated)
+		require.Contains(t, err.Error(), "decorator[0]")
+	})
+
+	t.Run("isolate", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(2)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out", conveyer.WithIsolation())
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "other", "result")
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		require.NoError(t, pipeline.Send("in", "no decorator"))
+		require.NoError(t, pipeline.Send("other", "b"))
+
+		data, err := pipeline.Recv("result")
+		require.NoError(t, err)
+		require.Equal(t, "decorated: b", data)
+
+		_, err = pipeline.Recv("out")
+		require.ErrorIs(t, err, conveyer.ErrClosed)
+
+		require.ErrorIs(t, harness.Stop(), handlers.ErrCantBeDecorated)
+	})
+
+	t.Run("zero max restarts", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(4)
+		pipeline.RegisterDecorator(
+			handlers.PrefixDecoratorFunc, "in", "out",
+			conveyer.WithRestart(time.Millisecond, time.Millisecond),
+			conveyer.WithMaxRestarts(0),
+		)
+
+		events, unsubscribe := pipeline.Subscribe(16)
+		defer unsubscribe()
+
+		require.NoError(t, pipeline.Send("in", "no decorator"))
+		require.ErrorIs(t, pipeline.Run(context.Background()), handlers.ErrCantBeDecorated)
+
+		for len(events) > 0 {
+			require.NotEqual(t, conveyer.EventHandlerRestarting, (<-events).Kind)
+		}
+	})
+
+	t.Run("cancel during backoff", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(2)
+		pipeline.RegisterDecorator(
+			handlers.PrefixDecoratorFunc, "in", "out",
+			conveyer.WithRestart(time.Hour, time.Hour),
+		)
+
+		events, unsubscribe := pipeline.Subscribe(16)
+		defer unsubscribe()
+
+		harness := conveyertest.New(t, pipeline)
+		harness.Start()
+
+		require.NoError(t, pipeline.Send("in", "no decorator"))
+
+		for event := range events {
+			if event.Kind == conveyer.EventHandlerRestarting {
+				require.ErrorIs(t, event.Err, handlers.ErrCantBeDecorated)
+				require.Equal(t, 1, event.Attempt)
+
+				break
+			}
+		}
+
+		require.ErrorIs(t, harness.Stop(), handlers.ErrCantBeDecorated)
+
+		_, err := pipeline.Recv("out")
+		require.ErrorIs(t, err, conveyer.ErrClosed)
+	})
+}
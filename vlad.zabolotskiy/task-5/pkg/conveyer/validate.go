@@ -0,0 +1,274 @@
+package conveyer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+var (
+	ErrMultipleWriters = errors.New("channel has multiple writers")
+	ErrOrphanInput     = errors.New("channel has no writer and is not a declared input")
+	ErrOrphanOutput    = errors.New("channel has no reader and is not a declared output")
+	ErrCycle           = errors.New("handlers form a cycle")
+	ErrUnreachable     = errors.New("handler is unreachable from any input")
+)
+
+type ValidationError struct {
+	Err      error
+	Channel  string
+	Handlers []string
+}
+
+func (e *ValidationError) Error() string {
+	switch {
+	case e.Channel != "" && len(e.Handlers) > 0:
+		return fmt.Sprintf("%s: channel %q, handlers %v", e.Err, e.Channel, e.Handlers)
+	case e.Channel != "":
+		return fmt.Sprintf("%s: channel %q", e.Err, e.Channel)
+	default:
+		return fmt.Sprintf("%s: handlers %v", e.Err, e.Handlers)
+	}
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+type topology struct {
+	handlers        []handlerSpec
+	writers         map[string][]int
+	readers         map[string][]int
+	declaredInputs  []string
+	declaredOutputs []string
+}
+
+func (c *Conveyer[T]) Validate() error {
+	c.mutex.RLock()
+	topo := newTopology(c.specs(), c.declaredInputs, c.declaredOutputs)
+	_, shadowed := c.channels[c.deadLetterName]
+	shadowed = shadowed && c.deadLetters != nil
+	deadLetterName := c.deadLetterName
+	c.mutex.RUnlock()
+
+	err := topo.validate()
+
+	if shadowed {
+		err = errors.Join(err, &ValidationError{Err: ErrDeadLetterShadows, Channel: deadLetterName, Handlers: nil})
+	}
+
+	return err
+}
+
+func newTopology(handlers []handlerSpec, declaredInputs, declaredOutputs []string) topology {
+	topo := topology{
+		handlers:        handlers,
+		writers:         make(map[string][]int),
+		readers:         make(map[string][]int),
+		declaredInputs:  declaredInputs,
+		declaredOutputs: declaredOutputs,
+	}
+
+	for index, registered := range handlers {
+		for _, name := range registered.inputs {
+			topo.readers[name] = append(topo.readers[name], index)
+		}
+
+		for _, name := range registered.outputs {
+			topo.writers[name] = append(topo.writers[name], index)
+		}
+	}
+
+	return topo
+}
+
+func (t topology) handlerName(index int) string {
+	return t.handlers[index].name
+}
+
+func (t topology) handlerNames(indexes []int) []string {
+	names := make([]string, len(indexes))
+
+	for position, index := range indexes {
+		names[position] = t.handlerName(index)
+	}
+
+	return names
+}
+
+func (t topology) channelNames() []string {
+	names := make([]string, 0, len(t.writers)+len(t.readers))
+
+	for name := range t.writers {
+		names = append(names, name)
+	}
+
+	for name := range t.readers {
+		if _, exists := t.writers[name]; !exists {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t topology) validate() error {
+	var errs []error
+
+	errs = append(errs, t.checkWriters()...)
+	errs = append(errs, t.checkOrphans()...)
+	errs = append(errs, t.checkCycles()...)
+	errs = append(errs, t.checkReachability()...)
+
+	return errors.Join(errs...)
+}
+
+func (t topology) checkWriters() []error {
+	var errs []error
+
+	for _, name := range t.channelNames() {
+		writers := t.writers[name]
+
+		if len(writers) > 1 || (len(writers) == 1 && slices.Contains(t.declaredInputs, name)) {
+			errs = append(errs, &ValidationError{
+				Err:      ErrMultipleWriters,
+				Channel:  name,
+				Handlers: t.handlerNames(writers),
+			})
+		}
+	}
+
+	return errs
+}
+
+func (t topology) checkOrphans() []error {
+	var errs []error
+
+	for _, name := range t.channelNames() {
+		if len(t.declaredInputs) > 0 && len(t.writers[name]) == 0 && !slices.Contains(t.declaredInputs, name) {
+			errs = append(errs, &ValidationError{
+				Err:      ErrOrphanInput,
+				Channel:  name,
+				Handlers: t.handlerNames(t.readers[name]),
+			})
+		}
+
+		if len(t.declaredOutputs) > 0 && len(t.readers[name]) == 0 && !slices.Contains(t.declaredOutputs, name) {
+			errs = append(errs, &ValidationError{
+				Err:      ErrOrphanOutput,
+				Channel:  name,
+				Handlers: t.handlerNames(t.writers[name]),
+			})
+		}
+	}
+
+	return errs
+}
+
+func (t topology) successors(index int) []int {
+	var next []int
+
+	for _, name := range t.handlers[index].outputs {
+		for _, reader := range t.readers[name] {
+			if !slices.Contains(next, reader) {
+				next = append(next, reader)
+			}
+		}
+	}
+
+	return next
+}
+
+func (t topology) checkCycles() []error {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+
+	var (
+		errs  []error
+		state = make([]int, len(t.handlers))
+		stack []int
+		visit func(index int)
+	)
+
+	visit = func(index int) {
+		state[index] = inProgress
+		stack = append(stack, index)
+
+		for _, next := range t.successors(index) {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case inProgress:
+				start := slices.Index(stack, next)
+				errs = append(errs, &ValidationError{
+					Err:      ErrCycle,
+					Channel:  "",
+					Handlers: t.handlerNames(slices.Clone(stack[start:])),
+				})
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[index] = done
+	}
+
+	for index := range t.handlers {
+		if state[index] == unvisited {
+			visit(index)
+		}
+	}
+
+	return errs
+}
+
+func (t topology) checkReachability() []error {
+	reached := make([]bool, len(t.handlers))
+	queue := make([]int, 0, len(t.handlers))
+
+	for index, registered := range t.handlers {
+		isSource := len(registered.inputs) == 0
+
+		for _, name := range registered.inputs {
+			if len(t.writers[name]) == 0 {
+				isSource = true
+			}
+		}
+
+		if isSource {
+			reached[index] = true
+			queue = append(queue, index)
+		}
+	}
+
+	for len(queue) > 0 {
+		index := queue[0]
+		queue = queue[1:]
+
+		for _, next := range t.successors(index) {
+			if !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var errs []error
+
+	for index, isReached := range reached {
+		if !isReached {
+			errs = append(errs, &ValidationError{
+				Err:      ErrUnreachable,
+				Channel:  "",
+				Handlers: []string{t.handlerName(index)},
+			})
+		}
+	}
+
+	return errs
+}
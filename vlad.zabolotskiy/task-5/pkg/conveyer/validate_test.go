@@ -0,0 +1,69 @@
+package conveyer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConveyer_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "decorated")
+		pipeline.RegisterSeparator(handlers.SeparatorFunc, "decorated", []string{"left", "right"})
+		pipeline.RegisterMultiplexer(handlers.MultiplexerFunc, []string{"left", "right"}, "out")
+		pipeline.DeclareInputs("in")
+		pipeline.DeclareOutputs("out")
+
+		require.NoError(t, pipeline.Validate())
+	})
+
+	t.Run("multiple writers", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "a", "out")
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "b", "out")
+
+		err := pipeline.Validate()
+		require.ErrorIs(t, err, conveyer.ErrMultipleWriters)
+		require.Contains(t, err.Error(), `"out"`)
+	})
+
+	t.Run("orphans", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "middle")
+		pipeline.RegisterMultiplexer(handlers.MultiplexerFunc, []string{"middle", "forgotten"}, "out")
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "out", "dangling")
+		pipeline.DeclareInputs("in")
+		pipeline.DeclareOutputs("out")
+
+		err := pipeline.Validate()
+		require.ErrorIs(t, err, conveyer.ErrOrphanInput)
+		require.ErrorIs(t, err, conveyer.ErrOrphanOutput)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := conveyer.New(1)
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "a", "b")
+		pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "b", "a")
+
+		err := pipeline.Validate()
+		require.ErrorIs(t, err, conveyer.ErrCycle)
+		require.ErrorIs(t, err, conveyer.ErrUnreachable)
+
+		require.ErrorIs(t, pipeline.Run(context.Background()), conveyer.ErrCycle)
+	})
+}
@@ -0,0 +1,617 @@
+package conveyertest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/se1lzor/task-5/internal/chanutil"
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+const (
+	defaultTimeout = time.Second
+	leakPollPeriod = 10 * time.Millisecond
+	labelKey       = "conveyertest"
+)
+
+var (
+	ErrInjected = errors.New("injected failure")
+
+	harnessIDs atomic.Int64
+)
+
+type TB interface {
+	Helper()
+	Cleanup(cleanup func())
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+type options struct {
+	timeout  time.Duration
+	stepping bool
+}
+
+type Option func(options *options)
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(options *options) {
+		options.timeout = timeout
+	}
+}
+
+func WithStepping() Option {
+	return func(options *options) {
+		options.stepping = true
+	}
+}
+
+type Harness[T any] struct {
+	t        TB
+	pipeline *conveyer.Conveyer[T]
+	options  options
+	label    string
+	mutex    sync.Mutex
+	gates    map[string]*gate[T]
+	cancel   context.CancelFunc
+	done     chan error
+	stopped  bool
+	result   error
+}
+
+func New[T any](t TB, pipeline *conveyer.Conveyer[T], opts ...Option) *Harness[T] {
+	t.Helper()
+
+	parsed := options{timeout: defaultTimeout, stepping: false}
+
+	for _, option := range opts {
+		option(&parsed)
+	}
+
+	return &Harness[T]{
+		t:        t,
+		pipeline: pipeline,
+		options:  parsed,
+		label:    strconv.FormatInt(harnessIDs.Add(1), 10),
+		mutex:    sync.Mutex{},
+		gates:    make(map[string]*gate[T]),
+		cancel:   nil,
+		done:     nil,
+		stopped:  false,
+		result:   nil,
+	}
+}
+
+func (h *Harness[T]) Decorator(name string, decorator conveyer.DecoratorFunc[T]) conveyer.DecoratorFunc[T] {
+	run := h.wrap(name, func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+		return decorator(ctx, inputs[0], outputs[0])
+	})
+
+	return func(ctx context.Context, input chan T, output chan T) error {
+		return run(ctx, []chan T{input}, []chan T{output})
+	}
+}
+
+func (h *Harness[T]) Multiplexer(name string, multiplexer conveyer.MultiplexerFunc[T]) conveyer.MultiplexerFunc[T] {
+	run := h.wrap(name, func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+		return multiplexer(ctx, inputs, outputs[0])
+	})
+
+	return func(ctx context.Context, inputs []chan T, output chan T) error {
+		return run(ctx, inputs, []chan T{output})
+	}
+}
+
+func (h *Harness[T]) Separator(name string, separator conveyer.SeparatorFunc[T]) conveyer.SeparatorFunc[T] {
+	run := h.wrap(name, func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+		return separator(ctx, inputs[0], outputs)
+	})
+
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		return run(ctx, []chan T{input}, outputs)
+	}
+}
+
+func (h *Harness[T]) Fail(name string, nth int, err error) {
+	gate := h.gate(name)
+
+	gate.mutex.Lock()
+	defer gate.mutex.Unlock()
+
+	gate.failures[nth] = err
+}
+
+func (h *Harness[T]) Start() {
+	h.t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	h.mutex.Lock()
+	h.cancel, h.done = cancel, done
+	h.mutex.Unlock()
+
+	go pprof.Do(ctx, pprof.Labels(labelKey, h.label), func(ctx context.Context) {
+		done <- h.pipeline.Run(ctx)
+	})
+
+	h.t.Cleanup(func() {
+		h.mutex.Lock()
+		stopped := h.stopped
+		h.mutex.Unlock()
+
+		if !stopped {
+			_ = h.Stop()
+		}
+	})
+}
+
+func (h *Harness[T]) Feed(channel string, values ...T) {
+	h.t.Helper()
+
+	for _, data := range values {
+		ctx, cancel := context.WithTimeout(context.Background(), h.options.timeout)
+		err := h.pipeline.SendContext(ctx, channel, data)
+		cancel()
+
+		if err != nil {
+			h.t.Fatalf("feeding %q: %v", channel, err)
+		}
+	}
+}
+
+func (h *Harness[T]) Step(name string) {
+	h.t.Helper()
+
+	gate := h.gate(name)
+	timer := time.NewTimer(h.options.timeout)
+
+	defer timer.Stop()
+
+	select {
+	case gate.steps <- struct{}{}:
+	case <-timer.C:
+		h.t.Fatalf("stepping %q: handler is not waiting for a message", name)
+	}
+
+	select {
+	case <-gate.taken:
+	case <-timer.C:
+		h.t.Fatalf("stepping %q: message was not taken", name)
+	}
+}
+
+func (h *Harness[T]) Expect(channel string, values ...T) {
+	h.t.Helper()
+
+	received := make([]T, 0, len(values))
+
+	for range values {
+		ctx, cancel := context.WithTimeout(context.Background(), h.options.timeout)
+		data, err := h.pipeline.RecvContext(ctx, channel)
+		cancel()
+
+		if err != nil {
+			h.t.Fatalf("expecting %v from %q, received %v: %v", values, channel, received, err)
+		}
+
+		received = append(received, data)
+	}
+
+	if !reflect.DeepEqual(values, received) {
+		h.t.Fatalf("unexpected output on %q: expected %v, received %v", channel, values, received)
+	}
+}
+
+func (h *Harness[T]) ExpectEmpty(channel string) {
+	h.t.Helper()
+
+	data, err := h.pipeline.TryRecv(channel)
+	if !errors.Is(err, conveyer.ErrNoDataAvailable) {
+		h.t.Fatalf("unexpected %v on %q: %v", data, channel, err)
+	}
+}
+
+func (h *Harness[T]) Stop() error {
+	h.t.Helper()
+
+	h.mutex.Lock()
+	cancel := h.cancel
+	h.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	return h.Wait()
+}
+
+func (h *Harness[T]) Wait() error {
+	h.t.Helper()
+
+	h.mutex.Lock()
+
+	if h.stopped || h.done == nil {
+		h.mutex.Unlock()
+
+		return h.result
+	}
+
+	done := h.done
+	h.mutex.Unlock()
+
+	timer := time.NewTimer(h.options.timeout)
+	defer timer.Stop()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-timer.C:
+		h.t.Fatalf("conveyer did not stop within %v", h.options.timeout)
+	}
+
+	h.mutex.Lock()
+	h.stopped, h.result = true, err
+	h.mutex.Unlock()
+
+	if leaks := h.Leaks(); len(leaks) > 0 {
+		h.t.Errorf("goroutines leaked after Run returned:\n%s", strings.Join(leaks, "\n"))
+	}
+
+	return err
+}
+
+func (h *Harness[T]) Leaks() []string {
+	deadline := time.Now().Add(h.options.timeout)
+
+	for {
+		leaks := labeledGoroutines(labelKey, h.label)
+		if len(leaks) == 0 || time.Now().After(deadline) {
+			return leaks
+		}
+
+		time.Sleep(leakPollPeriod)
+	}
+}
+
+func (h *Harness[T]) gate(name string) *gate[T] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	existing, exists := h.gates[name]
+	if !exists {
+		existing = newGate[T](h.options.stepping)
+		h.gates[name] = existing
+	}
+
+	return existing
+}
+
+func (h *Harness[T]) wrap(
+	name string,
+	run func(ctx context.Context, inputs []chan T, outputs []chan T) error,
+) func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+	return func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+		gate := h.gate(name)
+		handlerCtx, cancel := context.WithCancel(ctx)
+
+		defer cancel()
+
+		loop := newGateLoop(gate, inputs, outputs)
+		handlerDone := make(chan struct{})
+
+		var handlerErr error
+
+		go func() {
+			defer close(handlerDone)
+
+			handlerErr = run(handlerCtx, loop.gatedInputs, loop.gatedOutputs)
+		}()
+
+		injected := loop.run(ctx, handlerDone)
+
+		cancel()
+		loop.closeInputs()
+		<-handlerDone
+		gate.carry(loop.pending)
+
+		if injected != nil {
+			return fmt.Errorf("%s: %w", name, injected)
+		}
+
+		return handlerErr
+	}
+}
+
+type gate[T any] struct {
+	stepped  bool
+	mutex    sync.Mutex
+	received int
+	failures map[int]error
+	carried  map[int]T
+	steps    chan struct{}
+	taken    chan struct{}
+}
+
+func newGate[T any](stepped bool) *gate[T] {
+	return &gate[T]{
+		stepped:  stepped,
+		mutex:    sync.Mutex{},
+		received: 0,
+		failures: make(map[int]error),
+		carried:  make(map[int]T),
+		steps:    make(chan struct{}),
+		taken:    make(chan struct{}, 1),
+	}
+}
+
+func (g *gate[T]) next() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.received++
+
+	if err, exists := g.failures[g.received]; exists {
+		return fmt.Errorf("%w: message %d: %w", ErrInjected, g.received, err)
+	}
+
+	return nil
+}
+
+func (g *gate[T]) carry(pending []*T) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for index, data := range pending {
+		if data != nil {
+			g.carried[index] = *data
+		}
+	}
+}
+
+func (g *gate[T]) restore(count int) []*T {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	pending := make([]*T, count)
+
+	for index, data := range g.carried {
+		if index < count {
+			pending[index] = &data
+		}
+
+		delete(g.carried, index)
+	}
+
+	return pending
+}
+
+type gateLoop[T any] struct {
+	gate         *gate[T]
+	inputs       []chan T
+	outputs      []chan T
+	gatedInputs  []chan T
+	gatedOutputs []chan T
+	pending      []*T
+	inputClosed  []bool
+	outputClosed []bool
+	tokens       int
+	armed        error
+}
+
+func newGateLoop[T any](gate *gate[T], inputs []chan T, outputs []chan T) *gateLoop[T] {
+	return &gateLoop[T]{
+		gate:         gate,
+		inputs:       inputs,
+		outputs:      outputs,
+		gatedInputs:  chanutil.Make[T](len(inputs)),
+		gatedOutputs: chanutil.Make[T](len(outputs)),
+		pending:      gate.restore(len(inputs)),
+		inputClosed:  make([]bool, len(inputs)),
+		outputClosed: make([]bool, len(outputs)),
+		tokens:       0,
+		armed:        nil,
+	}
+}
+
+func (l *gateLoop[T]) cases(ctx context.Context, handlerDone <-chan struct{}) ([]reflect.SelectCase, []int) {
+	cases := []reflect.SelectCase{
+		chanutil.RecvCase(ctx.Done()),
+		chanutil.RecvCase(handlerDone),
+		{Dir: reflect.SelectRecv, Chan: reflect.Value{}, Send: reflect.Value{}},
+	}
+	events := []int{0, 0, 0}
+
+	if l.gate.stepped {
+		cases[2].Chan = reflect.ValueOf(l.gate.steps)
+	}
+
+	for index := range l.inputs {
+		switch {
+		case l.inputClosed[index]:
+			continue
+		case l.pending[index] == nil:
+			cases = append(cases, chanutil.RecvCase(l.inputs[index]))
+		case !l.gate.stepped || l.tokens > 0:
+			cases = append(cases, chanutil.SendCase(l.gatedInputs[index], *l.pending[index]))
+		default:
+			continue
+		}
+
+		events = append(events, index+1)
+	}
+
+	for index := range l.outputs {
+		if !l.outputClosed[index] {
+			cases = append(cases, chanutil.RecvCase(l.gatedOutputs[index]))
+			events = append(events, -index-1)
+		}
+	}
+
+	return cases, events
+}
+
+func (l *gateLoop[T]) run(ctx context.Context, handlerDone <-chan struct{}) error {
+	for {
+		cases, events := l.cases(ctx, handlerDone)
+		chosen, value, ok := reflect.Select(cases)
+
+		switch {
+		case chosen == 0:
+			return nil
+
+		case chosen == 1:
+			l.closeFinished()
+
+			return l.armed
+
+		case chosen == 2:
+			l.tokens++
+
+		case events[chosen] > 0:
+			index := events[chosen] - 1
+
+			if cases[chosen].Dir == reflect.SelectRecv {
+				l.accept(index, value, ok)
+
+				continue
+			}
+
+			if l.handOff(index) {
+				return l.armed
+			}
+
+		default:
+			index := -events[chosen] - 1
+
+			if !ok {
+				l.outputClosed[index] = true
+				close(l.outputs[index])
+
+				continue
+			}
+
+			if l.armed != nil {
+				return l.armed
+			}
+
+			data, _ := value.Interface().(T)
+
+			select {
+			case l.outputs[index] <- data:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func (l *gateLoop[T]) accept(index int, value reflect.Value, ok bool) {
+	if !ok {
+		l.inputClosed[index] = true
+		close(l.gatedInputs[index])
+
+		return
+	}
+
+	data, _ := value.Interface().(T)
+	l.pending[index] = &data
+}
+
+func (l *gateLoop[T]) handOff(index int) bool {
+	if l.gate.stepped {
+		l.tokens--
+
+		select {
+		case l.gate.taken <- struct{}{}:
+		default:
+		}
+	}
+
+	if l.armed != nil {
+		return true
+	}
+
+	l.pending[index] = nil
+	l.armed = l.gate.next()
+
+	return false
+}
+
+func (l *gateLoop[T]) closeFinished() {
+	for index, output := range l.gatedOutputs {
+		if l.outputClosed[index] {
+			continue
+		}
+
+		select {
+		case _, ok := <-output:
+			if !ok {
+				l.outputClosed[index] = true
+				close(l.outputs[index])
+			}
+		default:
+		}
+	}
+}
+
+func (l *gateLoop[T]) closeInputs() {
+	for index, input := range l.gatedInputs {
+		if !l.inputClosed[index] {
+			l.inputClosed[index] = true
+			close(input)
+		}
+	}
+}
+
+func labeledGoroutines(key string, value string) []string {
+	var buffer bytes.Buffer
+
+	if err := pprof.Lookup("goroutine").WriteTo(&buffer, 1); err != nil {
+		return []string{err.Error()}
+	}
+
+	label := fmt.Sprintf("%q:%q", key, value)
+
+	var (
+		stacks  []string
+		current []string
+	)
+
+	flush := func() {
+		if len(current) > 1 && strings.HasPrefix(current[1], "# labels:") && strings.Contains(current[1], label) {
+			stacks = append(stacks, strings.Join(current, "\n"))
+		}
+
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(&buffer)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			flush()
+
+			continue
+		}
+
+		current = append(current, line)
+	}
+
+	flush()
+
+	return stacks
+}
@@ -0,0 +1,168 @@
+package conveyertest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errBoom = errors.New("boom")
+
+func TestHarness_StepsHandlers(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	harness := conveyertest.New(t, pipeline, conveyertest.WithStepping())
+	pipeline.RegisterDecorator(harness.Decorator("prefix", handlers.PrefixDecoratorFunc), "in", "decorated")
+	pipeline.RegisterSeparator(harness.Separator("split", handlers.SeparatorFunc),
+		"decorated", []string{"left", "right"})
+
+	harness.Start()
+	harness.Feed("in", "a", "b", "c")
+
+	harness.Step("prefix")
+	harness.Step("prefix")
+	harness.ExpectEmpty("left")
+	harness.ExpectEmpty("right")
+
+	harness.Step("split")
+	harness.Expect("left", "decorated: a")
+	harness.ExpectEmpty("right")
+
+	harness.Step("split")
+	harness.Expect("right", "decorated: b")
+
+	harness.Step("prefix")
+	harness.Step("split")
+	harness.Expect("left", "decorated: c")
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestHarness_InjectsFailures(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	harness := conveyertest.New(t, pipeline)
+	pipeline.RegisterDecorator(harness.Decorator("prefix", handlers.PrefixDecoratorFunc), "in", "out",
+		conveyer.WithMaxRestarts(1), conveyer.WithRestart(time.Millisecond, time.Millisecond))
+	harness.Fail("prefix", 2, errBoom)
+
+	harness.Start()
+	harness.Feed("in", "a", "b", "c")
+	harness.Expect("out", "decorated: a", "decorated: c")
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestHarness_ReportsInjectedError(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	harness := conveyertest.New(t, pipeline)
+	pipeline.RegisterDecorator(harness.Decorator("prefix", handlers.PrefixDecoratorFunc), "in", "out")
+	harness.Fail("prefix", 1, errBoom)
+
+	harness.Start()
+	harness.Feed("in", "a")
+
+	err := harness.Wait()
+	require.ErrorIs(t, err, errBoom)
+	require.ErrorIs(t, err, conveyertest.ErrInjected)
+}
+
+type recordingTB struct {
+	testing.TB
+
+	mutex  sync.Mutex
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestHarness_DetectsLeaks(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	leaky := func(ctx context.Context, input chan string, output chan string) error {
+		go func() {
+			<-release
+		}()
+
+		return handlers.PrefixDecoratorFunc(ctx, input, output)
+	}
+
+	recorder := &recordingTB{TB: t, mutex: sync.Mutex{}, errors: nil}
+	pipeline := conveyer.New(1)
+	harness := conveyertest.New(recorder, pipeline, conveyertest.WithTimeout(50*time.Millisecond))
+	pipeline.RegisterDecorator(harness.Decorator("leaky", leaky), "in", "out")
+
+	harness.Start()
+	require.NoError(t, harness.Stop())
+
+	require.Len(t, recorder.errors, 1)
+	require.Contains(t, recorder.errors[0], "goroutines leaked")
+	require.True(t, strings.Contains(recorder.errors[0], "TestHarness_DetectsLeaks"))
+}
+
+type failureRecorder struct {
+	mutex    sync.Mutex
+	failures []string
+	cleanups []func()
+}
+
+func (r *failureRecorder) Helper() {}
+
+func (r *failureRecorder) Cleanup(cleanup func()) {
+	r.cleanups = append(r.cleanups, cleanup)
+}
+
+func (r *failureRecorder) Errorf(format string, args ...any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *failureRecorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestHarness_ReportsThroughTB(t *testing.T) {
+	t.Parallel()
+
+	recorder := &failureRecorder{mutex: sync.Mutex{}, failures: nil, cleanups: nil}
+	pipeline := conveyer.New(4)
+	harness := conveyertest.New(recorder, pipeline)
+	pipeline.RegisterDecorator(harness.Decorator("prefix", handlers.PrefixDecoratorFunc), "in", "out")
+
+	harness.Start()
+	harness.Feed("in", "a")
+	harness.Expect("out", "unexpected")
+
+	require.NoError(t, harness.Stop())
+
+	for _, cleanup := range recorder.cleanups {
+		cleanup()
+	}
+
+	require.Len(t, recorder.failures, 1)
+	require.Contains(t, recorder.failures[0], `unexpected output on "out"`)
+}
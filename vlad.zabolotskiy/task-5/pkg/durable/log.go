@@ -0,0 +1,547 @@
+package durable
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSegmentSize  = 4 << 20
+	defaultSyncInterval = time.Second
+	headerSize          = 8
+	offsetFileName      = "offset"
+	segmentExtension    = ".seg"
+	filePermissions     = 0o644
+	dirPermissions      = 0o755
+)
+
+var (
+	ErrClosed      = errors.New("log is closed")
+	ErrCorrupted   = errors.New("corrupted segment")
+	ErrOutOfRange  = errors.New("offset out of range")
+	ErrInvalidSize = errors.New("segment size must be positive")
+)
+
+type SyncPolicy int
+
+const (
+	SyncAlways SyncPolicy = iota
+	SyncInterval
+	SyncNever
+)
+
+type options struct {
+	segmentSize  int64
+	sync         SyncPolicy
+	syncInterval time.Duration
+}
+
+type Option func(options *options)
+
+func WithSegmentSize(bytes int64) Option {
+	return func(options *options) {
+		options.segmentSize = bytes
+	}
+}
+
+func WithSync(policy SyncPolicy) Option {
+	return func(options *options) {
+		options.sync = policy
+	}
+}
+
+func WithSyncInterval(interval time.Duration) Option {
+	return func(options *options) {
+		options.sync = SyncInterval
+		options.syncInterval = interval
+	}
+}
+
+type segment struct {
+	base  uint64
+	count uint64
+	size  int64
+	path  string
+}
+
+type Log struct {
+	dir      string
+	options  options
+	mutex    sync.Mutex
+	segments []*segment
+	active   *os.File
+	next     uint64
+	acked    uint64
+	lastSync time.Time
+	dirty    bool
+	closed   bool
+	stop     chan struct{}
+}
+
+func Open(dir string, opts ...Option) (*Log, error) {
+	parsed := options{
+		segmentSize:  defaultSegmentSize,
+		sync:         SyncAlways,
+		syncInterval: defaultSyncInterval,
+	}
+
+	for _, option := range opts {
+		option(&parsed)
+	}
+
+	if parsed.segmentSize <= 0 {
+		return nil, ErrInvalidSize
+	}
+
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+
+	log := &Log{
+		dir:      dir,
+		options:  parsed,
+		mutex:    sync.Mutex{},
+		segments: nil,
+		active:   nil,
+		next:     0,
+		acked:    0,
+		lastSync: time.Now(),
+		dirty:    false,
+		closed:   false,
+		stop:     make(chan struct{}),
+	}
+
+	if err := log.load(); err != nil {
+		return nil, err
+	}
+
+	if parsed.sync == SyncInterval && parsed.syncInterval > 0 {
+		go log.flushPeriodically()
+	}
+
+	return log, nil
+}
+
+func (l *Log) flushPeriodically() {
+	ticker := time.NewTicker(l.options.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mutex.Lock()
+
+			if l.dirty && !l.closed {
+				if err := l.active.Sync(); err == nil {
+					l.dirty, l.lastSync = false, time.Now()
+				}
+			}
+
+			l.mutex.Unlock()
+		}
+	}
+}
+
+func (l *Log) load() error {
+	acked, err := readOffset(filepath.Join(l.dir, offsetFileName))
+	if err != nil {
+		return err
+	}
+
+	l.acked, l.next = acked, acked
+
+	segments, err := listSegments(l.dir)
+	if err != nil {
+		return err
+	}
+
+	for index, current := range segments {
+		records, valid, err := readSegment(current.path)
+		if err != nil {
+			return err
+		}
+
+		if valid != current.size {
+			if index != len(segments)-1 {
+				return fmt.Errorf("%w: %s", ErrCorrupted, current.path)
+			}
+
+			if err := os.Truncate(current.path, valid); err != nil {
+				return fmt.Errorf("truncating torn segment tail: %w", err)
+			}
+
+			current.size = valid
+		}
+
+		current.count = uint64(len(records))
+		l.next = max(l.next, current.base+current.count)
+	}
+
+	l.segments = segments
+
+	if len(segments) == 0 {
+		return l.roll()
+	}
+
+	last := segments[len(segments)-1]
+
+	active, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, filePermissions)
+	if err != nil {
+		return fmt.Errorf("opening active segment: %w", err)
+	}
+
+	l.active = active
+
+	return nil
+}
+
+func (l *Log) roll() error {
+	if l.active != nil {
+		if err := l.active.Sync(); err != nil {
+			return fmt.Errorf("syncing segment: %w", err)
+		}
+
+		if err := l.active.Close(); err != nil {
+			return fmt.Errorf("closing segment: %w", err)
+		}
+	}
+
+	path := filepath.Join(l.dir, fmt.Sprintf("%020d%s", l.next, segmentExtension))
+
+	active, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermissions)
+	if err != nil {
+		return fmt.Errorf("creating segment: %w", err)
+	}
+
+	l.active = active
+	l.segments = append(l.segments, &segment{base: l.next, count: 0, size: 0, path: path})
+
+	return syncDir(l.dir)
+}
+
+func (l *Log) Append(payload []byte) (uint64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return 0, ErrClosed
+	}
+
+	current := l.segments[len(l.segments)-1]
+
+	if current.count > 0 && current.size+int64(headerSize+len(payload)) > l.options.segmentSize {
+		if err := l.roll(); err != nil {
+			return 0, err
+		}
+
+		current = l.segments[len(l.segments)-1]
+	}
+
+	record := make([]byte, headerSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload))) //nolint:gosec
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[headerSize:], payload)
+
+	if _, err := l.active.Write(record); err != nil {
+		return 0, l.dropRecord(current, fmt.Errorf("writing record: %w", err))
+	}
+
+	l.dirty = true
+
+	if err := l.syncActive(); err != nil {
+		return 0, l.dropRecord(current, err)
+	}
+
+	offset := l.next
+	l.next++
+	current.count++
+	current.size += int64(len(record))
+
+	return offset, nil
+}
+
+func (l *Log) dropRecord(current *segment, cause error) error {
+	if err := l.active.Truncate(current.size); err != nil {
+		return fmt.Errorf("%w (truncating torn record: %w)", cause, err)
+	}
+
+	return cause
+}
+
+func (l *Log) syncActive() error {
+	switch l.options.sync {
+	case SyncAlways:
+	case SyncInterval:
+		if time.Since(l.lastSync) < l.options.syncInterval {
+			return nil
+		}
+	case SyncNever:
+		return nil
+	}
+
+	if err := l.active.Sync(); err != nil {
+		return fmt.Errorf("syncing segment: %w", err)
+	}
+
+	l.dirty, l.lastSync = false, time.Now()
+
+	return nil
+}
+
+func (l *Log) Ack(offset uint64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	if offset >= l.next {
+		return fmt.Errorf("%w: %d", ErrOutOfRange, offset)
+	}
+
+	if offset < l.acked {
+		return nil
+	}
+
+	l.acked = offset + 1
+
+	if err := l.writeOffset(); err != nil {
+		return err
+	}
+
+	for len(l.segments) > 1 && l.segments[0].base+l.segments[0].count <= l.acked {
+		if err := os.Remove(l.segments[0].path); err != nil {
+			return fmt.Errorf("removing acknowledged segment: %w", err)
+		}
+
+		l.segments = l.segments[1:]
+	}
+
+	return nil
+}
+
+func (l *Log) writeOffset() error {
+	path := filepath.Join(l.dir, offsetFileName)
+	temporary := path + ".tmp"
+
+	file, err := os.OpenFile(temporary, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePermissions)
+	if err != nil {
+		return fmt.Errorf("writing offset: %w", err)
+	}
+
+	if _, err := file.WriteString(strconv.FormatUint(l.acked, 10)); err != nil {
+		file.Close()
+
+		return fmt.Errorf("writing offset: %w", err)
+	}
+
+	if l.options.sync != SyncNever {
+		if err := file.Sync(); err != nil {
+			file.Close()
+
+			return fmt.Errorf("syncing offset: %w", err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing offset: %w", err)
+	}
+
+	if err := os.Rename(temporary, path); err != nil {
+		return fmt.Errorf("replacing offset: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Log) Replay(visit func(offset uint64, payload []byte) error) error {
+	l.mutex.Lock()
+
+	if l.closed {
+		l.mutex.Unlock()
+
+		return ErrClosed
+	}
+
+	acked := l.acked
+	paths := make([]*segment, 0, len(l.segments))
+
+	for _, current := range l.segments {
+		if current.base+current.count > acked {
+			paths = append(paths, &segment{base: current.base, count: current.count, size: 0, path: current.path})
+		}
+	}
+
+	type entry struct {
+		offset  uint64
+		payload []byte
+	}
+
+	var pending []entry
+
+	for _, current := range paths {
+		records, _, err := readSegment(current.path)
+		if err != nil {
+			l.mutex.Unlock()
+
+			return err
+		}
+
+		for index, payload := range records[:current.count] {
+			if offset := current.base + uint64(index); offset >= acked {
+				pending = append(pending, entry{offset: offset, payload: payload})
+			}
+		}
+	}
+
+	l.mutex.Unlock()
+
+	for _, record := range pending {
+		if err := visit(record.offset, record.payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Log) Unacked() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.next - l.acked
+}
+
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return nil
+	}
+
+	l.closed = true
+	close(l.stop)
+
+	if err := l.active.Sync(); err != nil {
+		l.active.Close()
+
+		return fmt.Errorf("syncing segment: %w", err)
+	}
+
+	if err := l.active.Close(); err != nil {
+		return fmt.Errorf("closing segment: %w", err)
+	}
+
+	return nil
+}
+
+func readOffset(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("reading offset: %w", err)
+	}
+
+	offset, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: offset file: %w", ErrCorrupted, err)
+	}
+
+	return offset, nil
+}
+
+func listSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing segments: %w", err)
+	}
+
+	var segments []*segment
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExtension) {
+			continue
+		}
+
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("listing segments: %w", err)
+		}
+
+		segments = append(segments, &segment{
+			base:  base,
+			count: 0,
+			size:  info.Size(),
+			path:  filepath.Join(dir, name),
+		})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].base < segments[j].base
+	})
+
+	return segments, nil
+}
+
+func readSegment(path string) ([][]byte, int64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading segment: %w", err)
+	}
+
+	var (
+		records [][]byte
+		valid   int
+	)
+
+	for len(content)-valid >= headerSize {
+		length := int(binary.LittleEndian.Uint32(content[valid : valid+4]))
+		checksum := binary.LittleEndian.Uint32(content[valid+4 : valid+headerSize])
+		end := valid + headerSize + length
+
+		if end > len(content) || crc32.ChecksumIEEE(content[valid+headerSize:end]) != checksum {
+			break
+		}
+
+		records = append(records, content[valid+headerSize:end])
+		valid = end
+	}
+
+	return records, int64(valid), nil
+}
+
+func syncDir(dir string) error {
+	handle, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("opening log directory: %w", err)
+	}
+	defer handle.Close()
+
+	if err := handle.Sync(); err != nil {
+		return fmt.Errorf("syncing log directory: %w", err)
+	}
+
+	return nil
+}
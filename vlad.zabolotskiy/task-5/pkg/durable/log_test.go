@@ -0,0 +1,117 @@
+package durable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/durable"
+
+	"github.com/stretchr/testify/require"
+)
+
+func replayAll(t *testing.T, log *durable.Log) []string {
+	t.Helper()
+
+	var payloads []string
+
+	require.NoError(t, log.Replay(func(_ uint64, payload []byte) error {
+		payloads = append(payloads, string(payload))
+
+		return nil
+	}))
+
+	return payloads
+}
+
+func TestLog_ReplaysUnackedAfterReopen(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	log, err := durable.Open(dir)
+	require.NoError(t, err)
+
+	for _, payload := range []string{"a", "b", "c"} {
+		_, err := log.Append([]byte(payload))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, log.Ack(0))
+	require.NoError(t, log.Close())
+
+	reopened, err := durable.Open(dir)
+	require.NoError(t, err)
+
+	defer reopened.Close()
+
+	require.Equal(t, []string{"b", "c"}, replayAll(t, reopened))
+	require.Equal(t, uint64(2), reopened.Unacked())
+
+	offset, err := reopened.Append([]byte("d"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), offset)
+}
+
+func TestLog_TruncatesTornTail(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	log, err := durable.Open(dir, durable.WithSync(durable.SyncNever))
+	require.NoError(t, err)
+
+	_, err = log.Append([]byte("complete"))
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	file, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{42, 0, 0, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	reopened, err := durable.Open(dir)
+	require.NoError(t, err)
+
+	defer reopened.Close()
+
+	require.Equal(t, []string{"complete"}, replayAll(t, reopened))
+
+	_, err = reopened.Append([]byte("next"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"complete", "next"}, replayAll(t, reopened))
+}
+
+func TestLog_RemovesAcknowledgedSegments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	log, err := durable.Open(dir, durable.WithSegmentSize(16))
+	require.NoError(t, err)
+
+	defer log.Close()
+
+	for _, payload := range []string{"first", "second", "third"} {
+		_, err := log.Append([]byte(payload))
+		require.NoError(t, err)
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	require.NoError(t, err)
+	require.Len(t, segments, 3)
+
+	require.NoError(t, log.Ack(1))
+
+	segments, err = filepath.Glob(filepath.Join(dir, "*.seg"))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	require.Equal(t, []string{"third"}, replayAll(t, log))
+
+	require.ErrorIs(t, log.Ack(5), durable.ErrOutOfRange)
+}
@@ -3,7 +3,10 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
 )
 
 var ErrCantBeDecorated = errors.New("can't be decorated")
@@ -22,6 +25,10 @@ func PrefixDecoratorFunc(ctx context.Context, input chan string, output chan str
 			}
 
 			if strings.Contains(data, "no decorator") {
+				if conveyer.Reject(ctx, data, ErrCantBeDecorated.Error()) {
+					continue
+				}
+
 				return ErrCantBeDecorated
 			}
 
@@ -45,8 +52,13 @@ func SeparatorFunc(ctx context.Context, input chan string, outputs []chan string
 		}
 	}()
 
+	state := conveyer.StateFrom(ctx)
 	counter := 0
 
+	if _, err := state.Get("counter", &counter); err != nil {
+		return fmt.Errorf("restoring separator state: %w", err)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -58,13 +70,18 @@ func SeparatorFunc(ctx context.Context, input chan string, outputs []chan string
 			}
 
 			index := counter % len(outputs)
-			counter++
 
 			select {
 			case <-ctx.Done():
 				return nil
 			case outputs[index] <- data:
 			}
+
+			counter++
+
+			if err := state.Set("counter", counter); err != nil {
+				return fmt.Errorf("saving separator state: %w", err)
+			}
 		}
 	}
 }
@@ -91,6 +108,8 @@ func MultiplexerFunc(ctx context.Context, inputs []chan string, output chan stri
 					anyActive = true
 
 					if strings.Contains(data, "no multiplexer") {
+						conveyer.Reject(ctx, data, "no multiplexer")
+
 						continue
 					}
 
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/se1lzor/task-5/internal/chanutil"
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+type mergeState[T any] struct {
+	inputs []chan T
+	heads  []T
+	ready  []bool
+	closed []bool
+}
+
+func newMergeState[T any](inputs []chan T) *mergeState[T] {
+	return &mergeState[T]{
+		inputs: inputs,
+		heads:  make([]T, len(inputs)),
+		ready:  make([]bool, len(inputs)),
+		closed: make([]bool, len(inputs)),
+	}
+}
+
+func (s *mergeState[T]) fill() {
+	for index, input := range s.inputs {
+		if s.ready[index] || s.closed[index] {
+			continue
+		}
+
+		select {
+		case data, ok := <-input:
+			s.accept(index, data, ok)
+		default:
+		}
+	}
+}
+
+func (s *mergeState[T]) accept(index int, data T, ok bool) {
+	if !ok {
+		s.closed[index] = true
+
+		return
+	}
+
+	s.heads[index] = data
+	s.ready[index] = true
+}
+
+func (s *mergeState[T]) anyReady() bool {
+	for _, isReady := range s.ready {
+		if isReady {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *mergeState[T]) wait(ctx context.Context) bool {
+	cases := []reflect.SelectCase{chanutil.RecvCase(ctx.Done())}
+	indexes := []int{-1}
+
+	for index, input := range s.inputs {
+		if !s.closed[index] {
+			cases = append(cases, chanutil.RecvCase(input))
+			indexes = append(indexes, index)
+		}
+	}
+
+	if len(cases) == 1 {
+		return false
+	}
+
+	chosen, value, ok := reflect.Select(cases)
+	if chosen == 0 {
+		return false
+	}
+
+	var data T
+	if ok {
+		data, _ = value.Interface().(T)
+	}
+
+	s.accept(indexes[chosen], data, ok)
+
+	return true
+}
+
+func merge[T any](
+	ctx context.Context,
+	inputs []chan T,
+	output chan T,
+	choose func(ready []bool) int,
+) error {
+	defer close(output)
+
+	state := newMergeState(inputs)
+
+	for {
+		state.fill()
+
+		if !state.anyReady() {
+			if !state.wait(ctx) {
+				return nil
+			}
+
+			continue
+		}
+
+		index := choose(state.ready)
+		data := state.heads[index]
+
+		var zero T
+
+		state.heads[index], state.ready[index] = zero, false
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case output <- data:
+		}
+	}
+}
+
+func FairMultiplexer[T any]() conveyer.MultiplexerFunc[T] {
+	return func(ctx context.Context, inputs []chan T, output chan T) error {
+		last := -1
+
+		return merge(ctx, inputs, output, func(ready []bool) int {
+			for offset := 1; offset <= len(ready); offset++ {
+				index := (last + offset) % len(ready)
+
+				if ready[index] {
+					last = index
+
+					return index
+				}
+			}
+
+			return 0
+		})
+	}
+}
+
+func PriorityMultiplexer[T any]() conveyer.MultiplexerFunc[T] {
+	return func(ctx context.Context, inputs []chan T, output chan T) error {
+		return merge(ctx, inputs, output, func(ready []bool) int {
+			for index, isReady := range ready {
+				if isReady {
+					return index
+				}
+			}
+
+			return 0
+		})
+	}
+}
+
+func WeightedMultiplexer[T any](weights ...int) conveyer.MultiplexerFunc[T] {
+	return func(ctx context.Context, inputs []chan T, output chan T) error {
+		if err := validateWeights(weights, len(inputs)); err != nil {
+			close(output)
+
+			return err
+		}
+
+		current := make([]int, len(weights))
+
+		return merge(ctx, inputs, output, func(ready []bool) int {
+			total, best := 0, -1
+
+			for index, weight := range weights {
+				if !ready[index] {
+					continue
+				}
+
+				total += weight
+				current[index] += weight
+
+				if best < 0 || current[index] > current[best] {
+					best = index
+				}
+			}
+
+			current[best] -= total
+
+			return best
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runMultiplexer(
+	t *testing.T,
+	multiplexer func(context.Context, []chan string, chan string) error,
+	inputs ...[]string,
+) []string {
+	t.Helper()
+
+	total := 0
+	channels := make([]chan string, len(inputs))
+
+	for index, messages := range inputs {
+		channels[index] = make(chan string, len(messages))
+
+		for _, message := range messages {
+			channels[index] <- message
+		}
+
+		close(channels[index])
+
+		total += len(messages)
+	}
+
+	output := make(chan string, total)
+
+	require.NoError(t, multiplexer(context.Background(), channels, output))
+
+	received := make([]string, 0, total)
+
+	for data := range output {
+		received = append(received, data)
+	}
+
+	return received
+}
+
+func TestMultiplexers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fair", func(t *testing.T) {
+		t.Parallel()
+
+		received := runMultiplexer(t, handlers.FairMultiplexer[string](), []string{"a1", "a2"}, []string{"b1", "b2"})
+		require.Equal(t, []string{"a1", "b1", "a2", "b2"}, received)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		t.Parallel()
+
+		received := runMultiplexer(t, handlers.PriorityMultiplexer[string](), []string{"high1", "high2"}, []string{"low"})
+		require.Equal(t, []string{"high1", "high2", "low"}, received)
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		t.Parallel()
+
+		received := runMultiplexer(t, handlers.WeightedMultiplexer[string](2, 1),
+			[]string{"a1", "a2", "a3", "a4"}, []string{"b1", "b2", "b3"})
+		require.Equal(t, []string{"a1", "b1", "a2", "a3", "b2", "a4", "b3"}, received)
+	})
+
+	t.Run("closes when inputs close", func(t *testing.T) {
+		t.Parallel()
+
+		received := runMultiplexer(t, handlers.FairMultiplexer[string]())
+		require.Empty(t, received)
+	})
+	t.Run("weights mismatch closes output", func(t *testing.T) {
+		t.Parallel()
+
+		output := make(chan string)
+		err := handlers.WeightedMultiplexer[string](1)(context.Background(), []chan string{}, output)
+		require.ErrorIs(t, err, handlers.ErrWeightsMismatch)
+
+		_, ok := <-output
+		require.False(t, ok)
+	})
+
+	t.Run("non-positive weight closes output", func(t *testing.T) {
+		t.Parallel()
+
+		output := make(chan string)
+		err := handlers.WeightedMultiplexer[string](1, 0)(context.Background(), []chan string{nil, nil}, output)
+		require.ErrorIs(t, err, handlers.ErrInvalidWeight)
+
+		_, ok := <-output
+		require.False(t, ok)
+	})
+}
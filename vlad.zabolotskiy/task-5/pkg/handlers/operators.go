@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/se1lzor/task-5/internal/chanutil"
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+var (
+	ErrInvalidSampleRate = errors.New("sample rate must be positive")
+	ErrSideOutputFull    = errors.New("side output is full")
+)
+
+func transform[T any](
+	ctx context.Context,
+	input chan T,
+	output chan T,
+	apply func(data T) []T,
+) error {
+	defer close(output)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case data, ok := <-input:
+			if !ok {
+				return nil
+			}
+
+			for _, result := range apply(data) {
+				select {
+				case <-ctx.Done():
+					return nil
+				case output <- result:
+				}
+			}
+		}
+	}
+}
+
+func Filter[T any](predicate func(data T) bool) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		return transform(ctx, input, output, func(data T) []T {
+			if !predicate(data) {
+				return nil
+			}
+
+			return []T{data}
+		})
+	}
+}
+
+func Map[T any](mapper func(data T) T) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		return transform(ctx, input, output, func(data T) []T {
+			return []T{mapper(data)}
+		})
+	}
+}
+
+func FlatMap[T any](mapper func(data T) []T) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		return transform(ctx, input, output, mapper)
+	}
+}
+
+type seenKey struct {
+	key string
+	at  time.Time
+}
+
+func Distinct[T any](window time.Duration, key func(data T) string) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		seen := make(map[string]struct{})
+
+		var order []seenKey
+
+		return transform(ctx, input, output, func(data T) []T {
+			now := time.Now()
+
+			for len(order) > 0 && now.Sub(order[0].at) >= window {
+				delete(seen, order[0].key)
+				order = order[1:]
+			}
+
+			id := key(data)
+			if _, exists := seen[id]; exists {
+				return nil
+			}
+
+			seen[id] = struct{}{}
+			order = append(order, seenKey{key: id, at: now})
+
+			return []T{data}
+		})
+	}
+}
+
+func Sample[T any](every int) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		if every <= 0 {
+			close(output)
+
+			return ErrInvalidSampleRate
+		}
+
+		counter := 0
+
+		return transform(ctx, input, output, func(data T) []T {
+			counter++
+
+			if (counter-1)%every != 0 {
+				return nil
+			}
+
+			return []T{data}
+		})
+	}
+}
+
+func Tee[T any]() conveyer.SeparatorFunc[T] {
+	return tee[T](false)
+}
+
+func LossyTee[T any]() conveyer.SeparatorFunc[T] {
+	return tee[T](true)
+}
+
+func tee[T any](lossy bool) conveyer.SeparatorFunc[T] {
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		defer chanutil.CloseAll(outputs)
+
+		if len(outputs) == 0 {
+			return nil
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case data, ok := <-input:
+				if !ok {
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case outputs[0] <- data:
+				}
+
+				for _, side := range outputs[1:] {
+					if lossy {
+						select {
+						case side <- data:
+						default:
+							conveyer.Reject(ctx, data, ErrSideOutputFull.Error())
+						}
+
+						continue
+					}
+
+					select {
+					case <-ctx.Done():
+						return nil
+					case side <- data:
+					}
+				}
+			}
+		}
+	}
+}
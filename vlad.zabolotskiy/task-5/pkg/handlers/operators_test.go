@@ -0,0 +1,227 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runDecorator(t *testing.T, decorator conveyer.DecoratorFunc[string], messages ...string) []string {
+	t.Helper()
+
+	input := make(chan string, len(messages))
+	output := make(chan string, len(messages)*4)
+
+	for _, message := range messages {
+		input <- message
+	}
+
+	close(input)
+
+	require.NoError(t, decorator(context.Background(), input, output))
+
+	received := []string{}
+
+	for data := range output {
+		received = append(received, data)
+	}
+
+	return received
+}
+
+func TestOperators(t *testing.T) {
+	t.Parallel()
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		isShort := func(data string) bool {
+			return len(data) < 3
+		}
+
+		require.Equal(t, []string{"a", "bb"}, runDecorator(t, handlers.Filter(isShort), "a", "bb", "ccc"))
+	})
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, []string{"A", "B"}, runDecorator(t, handlers.Map(strings.ToUpper), "a", "b"))
+	})
+
+	t.Run("flat map", func(t *testing.T) {
+		t.Parallel()
+
+		split := func(data string) []string {
+			return strings.Split(data, ",")
+		}
+
+		require.Equal(t, []string{"a", "b", "c"}, runDecorator(t, handlers.FlatMap(split), "a,b", "c"))
+	})
+
+	t.Run("distinct", func(t *testing.T) {
+		t.Parallel()
+
+		identity := func(data string) string {
+			return data
+		}
+
+		received := runDecorator(t, handlers.Distinct(time.Hour, identity), "a", "b", "a", "c", "b")
+		require.Equal(t, []string{"a", "b", "c"}, received)
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		t.Parallel()
+
+		received := runDecorator(t, handlers.Sample[string](2), "1", "2", "3", "4", "5")
+		require.Equal(t, []string{"1", "3", "5"}, received)
+	})
+}
+
+func TestDistinct_WindowExpires(t *testing.T) {
+	t.Parallel()
+
+	identity := func(data string) string {
+		return data
+	}
+
+	input := make(chan string)
+	output := make(chan string, 3)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- handlers.Distinct(20*time.Millisecond, identity)(context.Background(), input, output)
+	}()
+
+	input <- "a"
+	require.Equal(t, "a", <-output)
+
+	require.Eventually(t, func() bool {
+		input <- "a"
+
+		select {
+		case data := <-output:
+			return data == "a"
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	close(input)
+	require.NoError(t, <-done)
+
+	for data := range output {
+		require.Equal(t, "a", data)
+	}
+}
+
+func TestSample_InvalidRate(t *testing.T) {
+	t.Parallel()
+
+	output := make(chan string)
+
+	err := handlers.Sample[string](0)(context.Background(), make(chan string), output)
+	require.ErrorIs(t, err, handlers.ErrInvalidSampleRate)
+
+	_, ok := <-output
+	require.False(t, ok)
+}
+
+func TestTee(t *testing.T) {
+	t.Parallel()
+
+	received := runSeparator(t, handlers.Tee[string](), 2, "a", "b")
+	require.Equal(t, [][]string{{"a", "b"}, {"a", "b"}}, received)
+
+	input := make(chan string, 2)
+	primary := make(chan string, 2)
+	side := make(chan string)
+
+	input <- "a"
+	input <- "b"
+	close(input)
+
+	var rejected []string
+
+	ctx := conveyer.WithRejecter(context.Background(), func(_ context.Context, data string, _ string) bool {
+		rejected = append(rejected, data)
+
+		return true
+	})
+
+	require.NoError(t, handlers.LossyTee[string]()(ctx, input, []chan string{primary, side}))
+	require.Equal(t, "a", <-primary)
+	require.Equal(t, "b", <-primary)
+	require.Equal(t, []string{"a", "b"}, rejected)
+}
+
+func TestTee_ThroughConveyer(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterSeparator(handlers.Tee[string](), "in", []string{"primary", "side"})
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	messages := []string{"a", "b", "c", "d", "e"}
+	sent := make(chan error, 1)
+
+	go func() {
+		for _, data := range messages {
+			if err := pipeline.Send("in", data); err != nil {
+				sent <- err
+
+				return
+			}
+		}
+
+		sent <- nil
+	}()
+
+	var primary, side []string
+
+	for range messages {
+		data, err := pipeline.Recv("primary")
+		require.NoError(t, err)
+
+		primary = append(primary, data)
+
+		data, err = pipeline.Recv("side")
+		require.NoError(t, err)
+
+		side = append(side, data)
+	}
+
+	require.NoError(t, <-sent)
+	require.Equal(t, messages, primary)
+	require.Equal(t, messages, side)
+
+	_, err := pipeline.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, harness.Wait())
+}
+
+func TestOperators_Cancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	output := make(chan string)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- handlers.Map(strings.ToUpper)(ctx, make(chan string), output)
+	}()
+
+	cancel()
+	require.NoError(t, <-done)
+
+	_, ok := <-output
+	require.False(t, ok)
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+
+	"github.com/se1lzor/task-5/internal/chanutil"
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+var (
+	ErrWeightsMismatch  = errors.New("weights count does not match outputs count")
+	ErrInvalidWeight    = errors.New("weight must be positive")
+	ErrNoMatchingOutput = errors.New("no output matches the message")
+)
+
+func separate[T any](
+	ctx context.Context,
+	input chan T,
+	outputs []chan T,
+	route func(data T) ([]int, error),
+) error {
+	defer chanutil.CloseAll(outputs)
+
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case data, ok := <-input:
+			if !ok {
+				return nil
+			}
+
+			indexes, err := route(data)
+			if err != nil {
+				return err
+			}
+
+			for _, index := range indexes {
+				select {
+				case <-ctx.Done():
+					return nil
+				case outputs[index] <- data:
+				}
+			}
+		}
+	}
+}
+
+func HashSeparator[T any](key func(data T) string) conveyer.SeparatorFunc[T] {
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		return separate(ctx, input, outputs, func(data T) ([]int, error) {
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(key(data)))
+
+			return []int{int(hash.Sum32() % uint32(len(outputs)))}, nil
+		})
+	}
+}
+
+func WeightedSeparator[T any](weights ...int) conveyer.SeparatorFunc[T] {
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		if err := validateWeights(weights, len(outputs)); err != nil {
+			chanutil.CloseAll(outputs)
+
+			return err
+		}
+
+		total := 0
+		for _, weight := range weights {
+			total += weight
+		}
+
+		current := make([]int, len(weights))
+
+		return separate(ctx, input, outputs, func(T) ([]int, error) {
+			best := 0
+
+			for index, weight := range weights {
+				current[index] += weight
+
+				if current[index] > current[best] {
+					best = index
+				}
+			}
+
+			current[best] -= total
+
+			return []int{best}, nil
+		})
+	}
+}
+
+func validateWeights(weights []int, count int) error {
+	if len(weights) != count {
+		return ErrWeightsMismatch
+	}
+
+	for _, weight := range weights {
+		if weight <= 0 {
+			return fmt.Errorf("%w: %d", ErrInvalidWeight, weight)
+		}
+	}
+
+	return nil
+}
+
+func LeastLoadedSeparator[T any]() conveyer.SeparatorFunc[T] {
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		defer chanutil.CloseAll(outputs)
+
+		if len(outputs) == 0 {
+			return nil
+		}
+
+		cases := make([]reflect.SelectCase, len(outputs)+1)
+		cases[len(outputs)] = chanutil.RecvCase(ctx.Done())
+		next := 0
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case data, ok := <-input:
+				if !ok {
+					return nil
+				}
+
+				index, sent, err := sendLeastLoaded(ctx, outputs, next, data)
+				if err != nil {
+					return nil
+				}
+
+				if sent {
+					next = index + 1
+
+					continue
+				}
+
+				for index, out := range outputs {
+					cases[index] = chanutil.SendCase(out, data)
+				}
+
+				chosen, _, _ := reflect.Select(cases)
+				if chosen == len(outputs) {
+					return nil
+				}
+
+				next = chosen + 1
+			}
+		}
+	}
+}
+
+func sendLeastLoaded[T any](ctx context.Context, outputs []chan T, start int, data T) (int, bool, error) {
+	order := make([]int, len(outputs))
+	rooms := make([]int, len(outputs))
+	reported := true
+
+	for offset := range outputs {
+		index := (start + offset) % len(outputs)
+		order[offset] = index
+
+		room, ok := conveyer.OutputRoom(ctx, index)
+		if !ok {
+			room, reported = cap(outputs[index])-len(outputs[index]), false
+		}
+
+		rooms[index] = room
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return rooms[order[i]] > rooms[order[j]]
+	})
+
+	if reported && rooms[order[0]] > 0 {
+		select {
+		case <-ctx.Done():
+			return 0, false, ctx.Err() //nolint:wrapcheck
+		case outputs[order[0]] <- data:
+			return order[0], true, nil
+		}
+	}
+
+	for _, index := range order {
+		select {
+		case outputs[index] <- data:
+			return index, true, nil
+		default:
+		}
+	}
+
+	return 0, false, nil
+}
+
+func PredicateSeparator[T any](predicates ...func(data T) bool) conveyer.SeparatorFunc[T] {
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		return separate(ctx, input, outputs, func(data T) ([]int, error) {
+			for index, predicate := range predicates {
+				if index < len(outputs) && predicate(data) {
+					return []int{index}, nil
+				}
+			}
+
+			if len(predicates) < len(outputs) {
+				return []int{len(predicates)}, nil
+			}
+
+			if !conveyer.Reject(ctx, data, ErrNoMatchingOutput.Error()) {
+				return nil, ErrNoMatchingOutput
+			}
+
+			return nil, nil
+		})
+	}
+}
+
+func BroadcastSeparator[T any]() conveyer.SeparatorFunc[T] {
+	return func(ctx context.Context, input chan T, outputs []chan T) error {
+		everyOutput := make([]int, len(outputs))
+		for index := range outputs {
+			everyOutput[index] = index
+		}
+
+		return separate(ctx, input, outputs, func(T) ([]int, error) {
+			return everyOutput, nil
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runSeparator(
+	t *testing.T,
+	separator func(context.Context, chan string, []chan string) error,
+	outputsCount int,
+	messages ...string,
+) [][]string {
+	t.Helper()
+
+	input := make(chan string, len(messages))
+	outputs := make([]chan string, outputsCount)
+
+	for index := range outputs {
+		outputs[index] = make(chan string, len(messages)*outputsCount)
+	}
+
+	for _, message := range messages {
+		input <- message
+	}
+
+	close(input)
+
+	require.NoError(t, separator(context.Background(), input, outputs))
+
+	received := make([][]string, outputsCount)
+
+	for index, output := range outputs {
+		for data := range output {
+			received[index] = append(received[index], data)
+		}
+	}
+
+	return received
+}
+
+func TestSeparators(t *testing.T) {
+	t.Parallel()
+
+	t.Run("hash", func(t *testing.T) {
+		t.Parallel()
+
+		key := func(data string) string {
+			return strings.SplitN(data, ":", 2)[0]
+		}
+
+		received := runSeparator(t, handlers.HashSeparator(key), 3, "a:1", "b:1", "a:2", "b:2", "a:3")
+
+		outputByKey := make(map[string]int)
+
+		for index, output := range received {
+			for _, data := range output {
+				if previous, exists := outputByKey[key(data)]; exists {
+					require.Equal(t, previous, index)
+				}
+
+				outputByKey[key(data)] = index
+			}
+		}
+
+		require.Len(t, outputByKey, 2)
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		t.Parallel()
+
+		received := runSeparator(t, handlers.WeightedSeparator[string](2, 1), 2, "1", "2", "3", "4", "5", "6")
+		require.Len(t, received[0], 4)
+		require.Len(t, received[1], 2)
+
+		outputs := []chan string{make(chan string), make(chan string)}
+		err := handlers.WeightedSeparator[string](1)(context.Background(), make(chan string), outputs)
+		require.ErrorIs(t, err, handlers.ErrWeightsMismatch)
+
+		for _, output := range outputs {
+			_, ok := <-output
+			require.False(t, ok)
+		}
+
+		outputs = []chan string{make(chan string), make(chan string)}
+		err = handlers.WeightedSeparator[string](2, -1)(context.Background(), make(chan string), outputs)
+		require.ErrorIs(t, err, handlers.ErrInvalidWeight)
+
+		for _, output := range outputs {
+			_, ok := <-output
+			require.False(t, ok)
+		}
+	})
+
+	t.Run("least loaded", func(t *testing.T) {
+		t.Parallel()
+
+		received := runSeparator(t, handlers.LeastLoadedSeparator[string](), 2, "1", "2", "3", "4")
+		require.Equal(t, [][]string{{"1", "3"}, {"2", "4"}}, received)
+	})
+
+	t.Run("predicate", func(t *testing.T) {
+		t.Parallel()
+
+		isNumber := func(data string) bool { return strings.Trim(data, "0123456789") == "" }
+
+		received := runSeparator(t, handlers.PredicateSeparator(isNumber), 2, "1", "a", "22", "b")
+		require.Equal(t, [][]string{{"1", "22"}, {"a", "b"}}, received)
+	})
+
+	t.Run("predicate without match", func(t *testing.T) {
+		t.Parallel()
+
+		isNumber := func(data string) bool { return strings.Trim(data, "0123456789") == "" }
+
+		input := make(chan string, 1)
+		input <- "a"
+
+		err := handlers.PredicateSeparator(isNumber)(context.Background(), input, []chan string{make(chan string, 1)})
+		require.ErrorIs(t, err, handlers.ErrNoMatchingOutput)
+	})
+
+	t.Run("broadcast", func(t *testing.T) {
+		t.Parallel()
+
+		received := runSeparator(t, handlers.BroadcastSeparator[string](), 3, "x", "y")
+		require.Equal(t, [][]string{{"x", "y"}, {"x", "y"}, {"x", "y"}}, received)
+	})
+}
+
+func TestLeastLoadedSeparator_Conveyer(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(1)
+	pipeline.RegisterSeparator(handlers.LeastLoadedSeparator[string](), "in", []string{"stalled", "active"})
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	recvCtx, recvCancel := context.WithTimeout(context.Background(), time.Second)
+	defer recvCancel()
+
+	go func() {
+		for _, data := range []string{"1", "2", "3", "4", "5", "6"} {
+			if err := pipeline.SendContext(recvCtx, "in", data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for range 4 {
+		_, err := pipeline.RecvContext(recvCtx, "active")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestLeastLoadedSeparator_ConveyerPressure(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(2)
+	pipeline.RegisterSeparator(handlers.LeastLoadedSeparator[string](), "in", []string{"full", "free"})
+
+	for _, data := range []string{"a", "b"} {
+		require.NoError(t, pipeline.Send("full", data))
+	}
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	recvCtx, recvCancel := context.WithTimeout(context.Background(), time.Second)
+	defer recvCancel()
+
+	for _, data := range []string{"1", "2"} {
+		require.NoError(t, pipeline.SendContext(recvCtx, "in", data))
+	}
+
+	for _, expected := range []string{"1", "2"} {
+		data, err := pipeline.RecvContext(recvCtx, "free")
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	}
+
+	for _, expected := range []string{"a", "b"} {
+		data, err := pipeline.RecvContext(recvCtx, "full")
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	}
+
+	require.NoError(t, harness.Stop())
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/handlers"
+)
+
+type Registry[T any] struct {
+	decorators   map[string]conveyer.DecoratorFunc[T]
+	multiplexers map[string]conveyer.MultiplexerFunc[T]
+	separators   map[string]conveyer.SeparatorFunc[T]
+}
+
+func NewRegistry[T any]() *Registry[T] {
+	return &Registry[T]{
+		decorators:   make(map[string]conveyer.DecoratorFunc[T]),
+		multiplexers: make(map[string]conveyer.MultiplexerFunc[T]),
+		separators:   make(map[string]conveyer.SeparatorFunc[T]),
+	}
+}
+
+func DefaultRegistry() *Registry[string] {
+	registry := NewRegistry[string]()
+
+	registry.Decorator("prefix-decorator", handlers.PrefixDecoratorFunc)
+
+	registry.Multiplexer("multiplexer", handlers.MultiplexerFunc)
+	registry.Multiplexer("fair-multiplexer", handlers.FairMultiplexer[string]())
+	registry.Multiplexer("priority-multiplexer", handlers.PriorityMultiplexer[string]())
+
+	registry.Separator("round-robin-separator", handlers.SeparatorFunc)
+	registry.Separator("least-loaded-separator", handlers.LeastLoadedSeparator[string]())
+	registry.Separator("broadcast-separator", handlers.BroadcastSeparator[string]())
+
+	return registry
+}
+
+func (r *Registry[T]) Decorator(name string, function conveyer.DecoratorFunc[T]) {
+	r.decorators[name] = function
+}
+
+func (r *Registry[T]) Multiplexer(name string, function conveyer.MultiplexerFunc[T]) {
+	r.multiplexers[name] = function
+}
+
+func (r *Registry[T]) Separator(name string, function conveyer.SeparatorFunc[T]) {
+	r.separators[name] = function
+}
+
+func (r *Registry[T]) lookup(handler HandlerSpec) error {
+	var exists bool
+
+	switch handler.Kind {
+	case conveyer.KindDecorator:
+		_, exists = r.decorators[handler.Function]
+	case conveyer.KindMultiplexer:
+		_, exists = r.multiplexers[handler.Function]
+	case conveyer.KindSeparator:
+		_, exists = r.separators[handler.Function]
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %s %q", ErrUnknownFunction, handler.Kind, handler.Function)
+	}
+
+	return nil
+}
+
+func Build[T any](
+	spec *Spec,
+	registry *Registry[T],
+	create func(size int) *conveyer.Conveyer[T],
+) (*conveyer.Conveyer[T], error) {
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("validate spec: %w", err)
+	}
+
+	var errs []error
+
+	for index, handler := range spec.Handlers {
+		if err := registry.lookup(handler); err != nil {
+			errs = append(errs, fmt.Errorf("handler %d: %w", index, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("resolve handlers: %w", err)
+	}
+
+	pipeline := create(spec.Size)
+
+	for _, handler := range spec.Handlers {
+		switch handler.Kind {
+		case conveyer.KindDecorator:
+			pipeline.RegisterDecorator(registry.decorators[handler.Function],
+				handler.Inputs[0], handler.Outputs[0], handler.options()...)
+		case conveyer.KindMultiplexer:
+			pipeline.RegisterMultiplexer(registry.multiplexers[handler.Function],
+				handler.Inputs, handler.Outputs[0], handler.options()...)
+		case conveyer.KindSeparator:
+			pipeline.RegisterSeparator(registry.separators[handler.Function],
+				handler.Inputs[0], handler.Outputs, handler.options()...)
+		}
+	}
+
+	pipeline.DeclareInputs(spec.Inputs...)
+	pipeline.DeclareOutputs(spec.Outputs...)
+
+	if spec.DeadLetter != "" {
+		if err := pipeline.SetDeadLetter(spec.DeadLetter); err != nil {
+			return nil, fmt.Errorf("set dead letter: %w", err)
+		}
+	}
+
+	if err := pipeline.Validate(); err != nil {
+		return nil, fmt.Errorf("validate topology: %w", err)
+	}
+
+	return pipeline, nil
+}
+
+func BuildStrings(spec *Spec, registry *Registry[string]) (*conveyer.StringConveyer, error) {
+	return Build(spec, registry, conveyer.New)
+}
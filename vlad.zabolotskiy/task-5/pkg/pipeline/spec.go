@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported spec format")
+	ErrInvalidSize       = errors.New("channel size must not be negative")
+	ErrUnknownKind       = errors.New("unknown handler kind")
+	ErrUnknownFunction   = errors.New("unknown handler function")
+	ErrInvalidPorts      = errors.New("invalid handler inputs or outputs")
+	ErrInvalidRestarts   = errors.New("max restarts must not be negative")
+)
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+type RestartSpec struct {
+	InitialBackoff string `json:"initial-backoff" yaml:"initial-backoff"`
+	MaxBackoff     string `json:"max-backoff"     yaml:"max-backoff"`
+	MaxRestarts    *int   `json:"max-restarts"    yaml:"max-restarts"`
+}
+
+type HandlerSpec struct {
+	Kind     conveyer.HandlerKind `json:"kind"     yaml:"kind"`
+	Function string               `json:"function" yaml:"function"`
+	Inputs   []string             `json:"inputs"   yaml:"inputs"`
+	Outputs  []string             `json:"outputs"  yaml:"outputs"`
+	Isolate  bool                 `json:"isolate"  yaml:"isolate"`
+	Restart  *RestartSpec         `json:"restart"  yaml:"restart"`
+}
+
+type Spec struct {
+	Size       int           `json:"size"        yaml:"size"`
+	Inputs     []string      `json:"inputs"      yaml:"inputs"`
+	Outputs    []string      `json:"outputs"     yaml:"outputs"`
+	DeadLetter string        `json:"dead-letter" yaml:"dead-letter"`
+	Handlers   []HandlerSpec `json:"handlers"    yaml:"handlers"`
+}
+
+func Load(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read spec: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return Parse(content, FormatYAML)
+	case ".json":
+		return Parse(content, FormatJSON)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+}
+
+func Parse(content []byte, format Format) (*Spec, error) {
+	var spec Spec
+
+	switch format {
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml: %w", err)
+		}
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("unmarshal json: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	return &spec, nil
+}
+
+func (s *Spec) Validate() error {
+	if s.Size < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidSize, s.Size)
+	}
+
+	var errs []error
+
+	for index, handler := range s.Handlers {
+		if err := handler.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("handler %d (%s): %w", index, handler.Function, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h HandlerSpec) validate() error {
+	switch h.Kind {
+	case conveyer.KindDecorator:
+		if len(h.Inputs) != 1 || len(h.Outputs) != 1 {
+			return fmt.Errorf("%w: decorator needs one input and one output", ErrInvalidPorts)
+		}
+	case conveyer.KindMultiplexer:
+		if len(h.Inputs) == 0 || len(h.Outputs) != 1 {
+			return fmt.Errorf("%w: multiplexer needs inputs and one output", ErrInvalidPorts)
+		}
+	case conveyer.KindSeparator:
+		if len(h.Inputs) != 1 || len(h.Outputs) == 0 {
+			return fmt.Errorf("%w: separator needs one input and outputs", ErrInvalidPorts)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownKind, h.Kind)
+	}
+
+	if h.Restart != nil {
+		for _, value := range []string{h.Restart.InitialBackoff, h.Restart.MaxBackoff} {
+			if _, err := parseDuration(value); err != nil {
+				return err
+			}
+		}
+
+		if h.Restart.MaxRestarts != nil && *h.Restart.MaxRestarts < 0 {
+			return fmt.Errorf("%w: %d", ErrInvalidRestarts, *h.Restart.MaxRestarts)
+		}
+	}
+
+	return nil
+}
+
+func (h HandlerSpec) options() []conveyer.HandlerOption {
+	var options []conveyer.HandlerOption
+
+	if h.Restart != nil {
+		initialBackoff, _ := parseDuration(h.Restart.InitialBackoff)
+		maxBackoff, _ := parseDuration(h.Restart.MaxBackoff)
+
+		options = append(options, conveyer.WithRestart(initialBackoff, maxBackoff))
+
+		if h.Restart.MaxRestarts != nil {
+			options = append(options, conveyer.WithMaxRestarts(*h.Restart.MaxRestarts))
+		}
+	}
+
+	if h.Isolate {
+		options = append(options, conveyer.WithIsolation())
+	}
+
+	return options
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration: %w", err)
+	}
+
+	return duration, nil
+}
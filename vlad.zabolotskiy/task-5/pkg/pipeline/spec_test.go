@@ -0,0 +1,163 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/pipeline"
+
+	"github.com/stretchr/testify/require"
+)
+
+const yamlSpec = `
+size: 2
+inputs: [in]
+outputs: [out]
+dead-letter: dlq
+handlers:
+  - kind: decorator
+    function: prefix-decorator
+    inputs: [in]
+    outputs: [decorated]
+    restart:
+      initial-backoff: 1ms
+      max-restarts: 3
+  - kind: separator
+    function: round-robin-separator
+    inputs: [decorated]
+    outputs: [left, right]
+  - kind: multiplexer
+    function: fair-multiplexer
+    inputs: [left, right]
+    outputs: [out]
+`
+
+func TestLoadAndBuild(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yamlSpec), 0o600))
+
+	spec, err := pipeline.Load(path)
+	require.NoError(t, err)
+	require.Len(t, spec.Handlers, 3)
+
+	built, err := pipeline.BuildStrings(spec, pipeline.DefaultRegistry())
+	require.NoError(t, err)
+
+	harness := conveyertest.New(t, built)
+	harness.Start()
+
+	require.NoError(t, built.Send("in", "a"))
+
+	data, err := built.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+}
+
+func TestBuild_ZeroMaxRestarts(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed")
+
+	var attempts atomic.Int32
+
+	registry := pipeline.NewRegistry[string]()
+	registry.Decorator("failing", func(context.Context, chan string, chan string) error {
+		attempts.Add(1)
+
+		return errFailed
+	})
+
+	spec, err := pipeline.Parse([]byte(`
+inputs: [in]
+handlers:
+  - kind: decorator
+    function: failing
+    inputs: [in]
+    outputs: [out]
+    restart:
+      initial-backoff: 1ms
+      max-restarts: 0
+`), pipeline.FormatYAML)
+	require.NoError(t, err)
+
+	built, err := pipeline.BuildStrings(spec, registry)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.ErrorIs(t, built.Run(ctx), errFailed)
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+func TestBuildErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown function", func(t *testing.T) {
+		t.Parallel()
+
+		spec, err := pipeline.Parse([]byte(`{
+			"inputs": ["in"],
+			"handlers": [{"kind": "decorator", "function": "missing", "inputs": ["in"], "outputs": ["out"]}]
+		}`), pipeline.FormatJSON)
+		require.NoError(t, err)
+
+		_, err = pipeline.BuildStrings(spec, pipeline.DefaultRegistry())
+		require.ErrorIs(t, err, pipeline.ErrUnknownFunction)
+	})
+
+	t.Run("invalid ports", func(t *testing.T) {
+		t.Parallel()
+
+		spec, err := pipeline.Parse([]byte(`{
+			"handlers": [{"kind": "decorator", "function": "prefix-decorator", "inputs": ["a", "b"], "outputs": ["out"]}]
+		}`), pipeline.FormatJSON)
+		require.NoError(t, err)
+
+		_, err = pipeline.BuildStrings(spec, pipeline.DefaultRegistry())
+		require.ErrorIs(t, err, pipeline.ErrInvalidPorts)
+	})
+
+	t.Run("invalid topology", func(t *testing.T) {
+		t.Parallel()
+
+		spec, err := pipeline.Parse([]byte(`
+handlers:
+  - {kind: decorator, function: prefix-decorator, inputs: [a], outputs: [b]}
+  - {kind: decorator, function: prefix-decorator, inputs: [b], outputs: [a]}
+`), pipeline.FormatYAML)
+		require.NoError(t, err)
+
+		_, err = pipeline.BuildStrings(spec, pipeline.DefaultRegistry())
+		require.ErrorIs(t, err, conveyer.ErrCycle)
+	})
+
+	t.Run("negative max restarts", func(t *testing.T) {
+		t.Parallel()
+
+		spec, err := pipeline.Parse([]byte(`
+handlers:
+  - {kind: decorator, function: prefix-decorator, inputs: [a], outputs: [b], restart: {max-restarts: -1}}
+`), pipeline.FormatYAML)
+		require.NoError(t, err)
+
+		_, err = pipeline.BuildStrings(spec, pipeline.DefaultRegistry())
+		require.ErrorIs(t, err, pipeline.ErrInvalidRestarts)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pipeline.Parse([]byte("sise: 1\n"), pipeline.FormatYAML)
+		require.Error(t, err)
+	})
+}
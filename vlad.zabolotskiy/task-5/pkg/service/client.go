@@ -0,0 +1,441 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const localChannelSize = 16
+
+var (
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	ErrStreamAborted    = errors.New("stream aborted by server")
+)
+
+type Client[T any] struct {
+	baseURL       string
+	http          *http.Client
+	mutex         sync.Mutex
+	registrations []func(local *conveyer.Conveyer[T])
+}
+
+func NewClient[T any](baseURL string, httpClient *http.Client) *Client[T] {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client[T]{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		http:          httpClient,
+		mutex:         sync.Mutex{},
+		registrations: nil,
+	}
+}
+
+func (c *Client[T]) register(registration func(local *conveyer.Conveyer[T])) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.registrations = append(c.registrations, registration)
+}
+
+func (c *Client[T]) newLocal() *conveyer.Conveyer[T] {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	local := conveyer.NewTyped[T](localChannelSize)
+
+	for _, registration := range c.registrations {
+		registration(local)
+	}
+
+	return local
+}
+
+func (c *Client[T]) RegisterDecorator(
+	decoratorFunction conveyer.DecoratorFunc[T],
+	input string,
+	output string,
+	options ...conveyer.HandlerOption,
+) {
+	c.register(func(local *conveyer.Conveyer[T]) {
+		local.RegisterDecorator(decoratorFunction, input, output, options...)
+	})
+}
+
+func (c *Client[T]) RegisterMultiplexer(
+	multiplexerFunction conveyer.MultiplexerFunc[T],
+	inputs []string,
+	output string,
+	options ...conveyer.HandlerOption,
+) {
+	c.register(func(local *conveyer.Conveyer[T]) {
+		local.RegisterMultiplexer(multiplexerFunction, inputs, output, options...)
+	})
+}
+
+func (c *Client[T]) RegisterSeparator(
+	separatorFunction conveyer.SeparatorFunc[T],
+	input string,
+	outputs []string,
+	options ...conveyer.HandlerOption,
+) {
+	c.register(func(local *conveyer.Conveyer[T]) {
+		local.RegisterSeparator(separatorFunction, input, outputs, options...)
+	})
+}
+
+// Run drives the handlers registered on the client. They run locally, read the
+// remote channels they take as inputs and send what they emit to the remote
+// channels named as their outputs. Without registered handlers there is nothing
+// to drive, and Run only checks that the server is up and waits for ctx. Each
+// call builds a fresh local conveyer, so Run can be called again after it
+// returns.
+func (c *Client[T]) Run(ctx context.Context) error {
+	response, err := c.do(ctx, http.MethodGet, "/healthz", "", nil)
+	if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+
+	local := c.newLocal()
+
+	sources, sinks := endpoints(local.Status().Handlers)
+	if len(sources) == 0 && len(sinks) == 0 {
+		<-ctx.Done()
+
+		return nil
+	}
+
+	events, unsubscribe := local.Subscribe(1)
+	defer unsubscribe()
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	group.Go(func() error {
+		return local.Run(groupCtx)
+	})
+
+	group.Go(func() error {
+		return c.pullAll(groupCtx, local, sources, events)
+	})
+
+	for _, name := range sinks {
+		group.Go(func() error {
+			return c.push(groupCtx, local, name)
+		})
+	}
+
+	return group.Wait() //nolint:wrapcheck
+}
+
+func endpoints(handlers []conveyer.HandlerStatus) ([]string, []string) {
+	readers := make(map[string]bool)
+	writers := make(map[string]bool)
+
+	for _, handler := range handlers {
+		for _, name := range handler.Inputs {
+			readers[name] = true
+		}
+
+		for _, name := range handler.Outputs {
+			writers[name] = true
+		}
+	}
+
+	var sources, sinks []string
+
+	for _, handler := range handlers {
+		for _, name := range handler.Inputs {
+			if !writers[name] && !slices.Contains(sources, name) {
+				sources = append(sources, name)
+			}
+		}
+
+		for _, name := range handler.Outputs {
+			if !readers[name] && !slices.Contains(sinks, name) {
+				sinks = append(sinks, name)
+			}
+		}
+	}
+
+	return sources, sinks
+}
+
+func (c *Client[T]) pullAll(
+	ctx context.Context,
+	local *conveyer.Conveyer[T],
+	sources []string,
+	events <-chan conveyer.Event,
+) error {
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for _, name := range sources {
+		group.Go(func() error {
+			return c.pull(groupCtx, local, name)
+		})
+	}
+
+	if err := group.Wait(); err != nil || ctx.Err() != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for running := false; !running; {
+		select {
+		case event := <-events:
+			running = event.Kind == conveyer.EventRunStarted
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if _, err := local.Shutdown(ctx); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("closing local handlers: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client[T]) pull(ctx context.Context, local *conveyer.Conveyer[T], name string) error {
+	for data, err := range c.StreamContext(ctx, name) {
+		if err == nil {
+			err = local.SendContext(ctx, name, data)
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("pulling %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client[T]) push(ctx context.Context, local *conveyer.Conveyer[T], name string) error {
+	for {
+		data, err := local.RecvContext(ctx, name)
+		if errors.Is(err, conveyer.ErrClosed) {
+			return nil
+		}
+
+		if err == nil {
+			err = c.SendContext(ctx, name, data)
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("pushing %q: %w", name, err)
+		}
+	}
+}
+
+func (c *Client[T]) Send(input string, data T) error {
+	return c.SendContext(context.Background(), input, data)
+}
+
+func (c *Client[T]) SendContext(ctx context.Context, input string, data T) error {
+	body, err := json.Marshal(message[T]{Data: data})
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	response, err := c.do(ctx, http.MethodPost, channelPath(input, "send"), contentTypeJSON, body)
+	if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+
+	return nil
+}
+
+func (c *Client[T]) SendBatch(ctx context.Context, input string, batch []T) (int, error) {
+	var body bytes.Buffer
+
+	encoder := json.NewEncoder(&body)
+
+	for _, data := range batch {
+		if err := encoder.Encode(message[T]{Data: data}); err != nil {
+			return 0, fmt.Errorf("encoding message: %w", err)
+		}
+	}
+
+	response, err := c.do(ctx, http.MethodPost, channelPath(input, "send"), contentTypeNDJSON, body.Bytes())
+	if err != nil {
+		var failure *responseError
+		if errors.As(err, &failure) {
+			return failure.response.Sent, err
+		}
+
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	var result sendResponse
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return result.Sent, nil
+}
+
+func (c *Client[T]) Recv(output string) (T, error) {
+	return c.RecvContext(context.Background(), output)
+}
+
+func (c *Client[T]) RecvContext(ctx context.Context, output string) (T, error) {
+	var received message[T]
+
+	response, err := c.do(ctx, http.MethodGet, channelPath(output, "recv"), "", nil)
+	if err != nil {
+		return received.Data, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(&received); err != nil {
+		return received.Data, fmt.Errorf("decoding message: %w", err)
+	}
+
+	return received.Data, nil
+}
+
+func (c *Client[T]) Stream(output string) iter.Seq2[T, error] {
+	return c.StreamContext(context.Background(), output)
+}
+
+func (c *Client[T]) StreamContext(ctx context.Context, output string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		response, err := c.do(ctx, http.MethodGet, channelPath(output, "stream"), "", nil)
+		if err != nil {
+			yield(zero, err)
+
+			return
+		}
+		defer response.Body.Close()
+
+		decoder := json.NewDecoder(response.Body)
+
+		for {
+			var received streamLine[T]
+
+			if err := decoder.Decode(&received); err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(zero, fmt.Errorf("streaming %q: %w", output, err))
+				}
+
+				return
+			}
+
+			if received.Error != "" {
+				yield(zero, fmt.Errorf("streaming %q: %w: %s", output, ErrStreamAborted, received.Error))
+
+				return
+			}
+
+			if !yield(received.Data, nil) {
+				return
+			}
+		}
+	}
+}
+
+type streamLine[T any] struct {
+	Data  T      `json:"data"`
+	Error string `json:"error"`
+}
+
+type responseError struct {
+	method   string
+	path     string
+	err      error
+	response errorResponse
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("%s %s: %v: %s", e.method, e.path, e.err, e.response.Error)
+}
+
+func (e *responseError) Unwrap() error {
+	return e.err
+}
+
+func (c *Client[T]) do(
+	ctx context.Context,
+	method string,
+	path string,
+	contentType string,
+	body []byte,
+) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+
+	if response.StatusCode == http.StatusOK {
+		return response, nil
+	}
+
+	defer response.Body.Close()
+
+	failure := &responseError{method: method, path: path, err: errorOf(response.StatusCode), response: errorResponse{}}
+
+	_ = json.NewDecoder(response.Body).Decode(&failure.response)
+
+	return nil, failure
+}
+
+func errorOf(status int) error {
+	switch status {
+	case http.StatusNotFound:
+		return conveyer.ErrChanNotFound
+	case http.StatusGone:
+		return conveyer.ErrClosed
+	case http.StatusServiceUnavailable:
+		return conveyer.ErrShutdown
+	case http.StatusTooManyRequests:
+		return conveyer.ErrChanFull
+	case http.StatusConflict:
+		return conveyer.ErrReservedForCalls
+	case http.StatusRequestTimeout:
+		return context.DeadlineExceeded
+	default:
+		return fmt.Errorf("%w %d", ErrUnexpectedStatus, status)
+	}
+}
+
+func channelPath(name string, action string) string {
+	return "/channels/" + url.PathEscape(name) + "/" + action
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeSSE    = "text/event-stream"
+)
+
+type message[T any] struct {
+	Data T `json:"data"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Sent  int    `json:"sent,omitempty"`
+}
+
+type sendResponse struct {
+	Sent int `json:"sent"`
+}
+
+type handlerStatus struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	Inputs    []string `json:"inputs"`
+	Outputs   []string `json:"outputs"`
+	State     string   `json:"state"`
+	Restarts  int      `json:"restarts"`
+	LastError string   `json:"last-error,omitempty"`
+}
+
+type statusResponse struct {
+	Healthy  bool            `json:"healthy"`
+	Running  bool            `json:"running"`
+	Shutdown bool            `json:"shutdown"`
+	Handlers []handlerStatus `json:"handlers"`
+}
+
+type Conveyer[T any] interface {
+	RegisterDecorator(fn conveyer.DecoratorFunc[T], input string, output string, options ...conveyer.HandlerOption)
+	RegisterMultiplexer(fn conveyer.MultiplexerFunc[T], inputs []string, output string, options ...conveyer.HandlerOption)
+	RegisterSeparator(fn conveyer.SeparatorFunc[T], input string, outputs []string, options ...conveyer.HandlerOption)
+
+	Run(ctx context.Context) error
+	Send(input string, data T) error
+	Recv(output string) (T, error)
+}
+
+var (
+	_ Conveyer[string] = (*conveyer.Conveyer[string])(nil)
+	_ Conveyer[string] = (*Client[string])(nil)
+)
+
+type Server[T any] struct {
+	pipeline *conveyer.Conveyer[T]
+	mux      *http.ServeMux
+}
+
+func NewServer[T any](pipeline *conveyer.Conveyer[T]) *Server[T] {
+	server := &Server[T]{pipeline: pipeline, mux: http.NewServeMux()}
+
+	server.mux.HandleFunc("GET /healthz", server.health)
+	server.mux.HandleFunc("GET /status", server.status)
+	server.mux.HandleFunc("POST /channels/{name}/send", server.send)
+	server.mux.HandleFunc("GET /channels/{name}/recv", server.recv)
+	server.mux.HandleFunc("GET /channels/{name}/stream", server.stream)
+
+	return server
+}
+
+func (s *Server[T]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.mux.ServeHTTP(writer, request)
+}
+
+func (s *Server[T]) health(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server[T]) status(writer http.ResponseWriter, _ *http.Request) {
+	status := s.pipeline.Status()
+	response := statusResponse{
+		Healthy:  status.Healthy(),
+		Running:  status.Running,
+		Shutdown: status.Shutdown,
+		Handlers: make([]handlerStatus, len(status.Handlers)),
+	}
+
+	for index, handler := range status.Handlers {
+		response.Handlers[index] = handlerStatus{
+			Name:      handler.Name,
+			Kind:      string(handler.Kind),
+			Inputs:    handler.Inputs,
+			Outputs:   handler.Outputs,
+			State:     string(handler.State),
+			Restarts:  handler.Restarts,
+			LastError: "",
+		}
+
+		if handler.LastError != nil {
+			response.Handlers[index].LastError = handler.LastError.Error()
+		}
+	}
+
+	code := http.StatusOK
+	if !response.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(writer, code, response)
+}
+
+func (s *Server[T]) send(writer http.ResponseWriter, request *http.Request) {
+	name := request.PathValue("name")
+	decoder := json.NewDecoder(request.Body)
+	sent := 0
+
+	for {
+		var incoming message[T]
+
+		err := decoder.Decode(&incoming)
+		if errors.Is(err, io.EOF) && (sent > 0 || isNDJSON(request.Header.Get("Content-Type"))) {
+			break
+		}
+
+		if err != nil {
+			writeJSON(writer, http.StatusBadRequest,
+				errorResponse{Error: fmt.Sprintf("decoding message: %v", err), Sent: sent})
+
+			return
+		}
+
+		if err := s.pipeline.SendContext(request.Context(), name, incoming.Data); err != nil {
+			writeJSON(writer, statusOf(err), errorResponse{Error: err.Error(), Sent: sent})
+
+			return
+		}
+
+		sent++
+
+		if !isNDJSON(request.Header.Get("Content-Type")) {
+			break
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, sendResponse{Sent: sent})
+}
+
+func (s *Server[T]) recv(writer http.ResponseWriter, request *http.Request) {
+	data, err := s.pipeline.RecvContext(request.Context(), request.PathValue("name"))
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, message[T]{Data: data})
+}
+
+func (s *Server[T]) stream(writer http.ResponseWriter, request *http.Request) {
+	name := request.PathValue("name")
+
+	if _, err := s.pipeline.Pressure(name); err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	events := strings.Contains(request.Header.Get("Accept"), contentTypeSSE)
+	flusher, _ := writer.(http.Flusher)
+
+	if events {
+		writer.Header().Set("Content-Type", contentTypeSSE)
+		writer.Header().Set("Cache-Control", "no-cache")
+	} else {
+		writer.Header().Set("Content-Type", contentTypeNDJSON)
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	for {
+		data, err := s.pipeline.RecvContext(request.Context(), name)
+		if err != nil {
+			endStream(writer, request, events, err)
+
+			return
+		}
+
+		encoded, err := json.Marshal(message[T]{Data: data})
+		if err != nil {
+			endStream(writer, request, events, fmt.Errorf("encoding message: %w", err))
+
+			return
+		}
+
+		if events {
+			fmt.Fprintf(writer, "data: %s\n\n", encoded)
+		} else {
+			fmt.Fprintf(writer, "%s\n", encoded)
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func endStream(writer http.ResponseWriter, request *http.Request, events bool, err error) {
+	switch {
+	case errors.Is(err, conveyer.ErrClosed):
+		if events {
+			fmt.Fprint(writer, "event: end\ndata: {}\n\n")
+		}
+	case request.Context().Err() != nil:
+	case events:
+		encoded, _ := json.Marshal(errorResponse{Error: err.Error(), Sent: 0})
+		fmt.Fprintf(writer, "event: error\ndata: %s\n\n", encoded)
+	default:
+		encoded, _ := json.Marshal(errorResponse{Error: err.Error(), Sent: 0})
+		fmt.Fprintf(writer, "%s\n", encoded)
+	}
+}
+
+func isNDJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && mediaType == contentTypeNDJSON
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, conveyer.ErrChanNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, conveyer.ErrClosed):
+		return http.StatusGone
+	case errors.Is(err, conveyer.ErrShutdown):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, conveyer.ErrChanFull):
+		return http.StatusTooManyRequests
+	case errors.Is(err, conveyer.ErrReservedForCalls):
+		return http.StatusConflict
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusRequestTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(writer http.ResponseWriter, err error) {
+	writeJSON(writer, statusOf(err), errorResponse{Error: err.Error(), Sent: 0})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	writer.WriteHeader(status)
+
+	_ = json.NewEncoder(writer).Encode(body)
+}
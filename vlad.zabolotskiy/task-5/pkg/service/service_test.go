@@ -0,0 +1,300 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+	"github.com/se1lzor/task-5/pkg/service"
+
+	"github.com/stretchr/testify/require"
+)
+
+func startService(t *testing.T) (*conveyer.Conveyer[string], *httptest.Server) {
+	t.Helper()
+
+	pipeline := conveyer.New(8)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	return pipeline, serve(t, pipeline)
+}
+
+func serve(t *testing.T, pipeline *conveyer.Conveyer[string]) *httptest.Server {
+	t.Helper()
+
+	runPipeline(t, pipeline)
+
+	server := httptest.NewServer(service.NewServer(pipeline))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func runPipeline(t *testing.T, pipeline *conveyer.Conveyer[string]) {
+	t.Helper()
+
+	events, unsubscribe := pipeline.Subscribe(16)
+	defer unsubscribe()
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for event := range events {
+		if event.Kind == conveyer.EventHandlerStarted {
+			break
+		}
+	}
+}
+
+func TestClient_SendRecv(t *testing.T) {
+	t.Parallel()
+
+	_, server := startService(t)
+
+	var client service.Conveyer[string] = service.NewClient[string](server.URL, server.Client())
+
+	require.NoError(t, client.Send("in", "a"))
+
+	data, err := client.Recv("out")
+	require.NoError(t, err)
+	require.Equal(t, "decorated: a", data)
+
+	require.ErrorIs(t, client.Send("missing", "a"), conveyer.ErrChanNotFound)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, client.Run(ctx))
+}
+
+func TestClient_BatchAndStream(t *testing.T) {
+	t.Parallel()
+
+	pipeline, server := startService(t)
+	client := service.NewClient[string](server.URL, server.Client())
+
+	sent, err := client.SendBatch(context.Background(), "in", []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, 3, sent)
+
+	shutdown := make(chan error, 1)
+
+	go func() {
+		_, err := pipeline.Shutdown(context.Background())
+		shutdown <- err
+	}()
+
+	var received []string
+
+	for data, err := range client.Stream("out") {
+		require.NoError(t, err)
+
+		received = append(received, data)
+	}
+
+	require.NoError(t, <-shutdown)
+	require.Equal(t, []string{"decorated: a", "decorated: b", "decorated: c"}, received)
+
+	_, err = client.Recv("out")
+	require.ErrorIs(t, err, conveyer.ErrClosed)
+	require.ErrorIs(t, client.Send("in", "late"), conveyer.ErrShutdown)
+}
+
+func TestClient_RunDrivesLocalHandlers(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(8)
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+	pipeline.RegisterDecorator(handlers.PrefixDecoratorFunc, "back", "final")
+
+	runPipeline(t, pipeline)
+
+	var streams atomic.Int32
+
+	handler := service.NewServer(pipeline)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.HasSuffix(request.URL.Path, "/stream") {
+			streams.Add(1)
+			defer streams.Add(-1)
+		}
+
+		handler.ServeHTTP(writer, request)
+	}))
+	t.Cleanup(server.Close)
+
+	var client service.Conveyer[string] = service.NewClient[string](server.URL, server.Client())
+
+	client.RegisterDecorator(func(ctx context.Context, input chan string, output chan string) error {
+		defer close(output)
+
+		for {
+			select {
+			case data, ok := <-input:
+				if !ok {
+					return nil
+				}
+
+				output <- strings.ToUpper(data)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}, "out", "back")
+
+	for _, sent := range []string{"a", "b"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+
+		go func() {
+			done <- client.Run(ctx)
+		}()
+
+		require.NoError(t, client.Send("in", sent))
+
+		data, err := client.Recv("final")
+		require.NoError(t, err)
+		require.Equal(t, "decorated: DECORATED: "+strings.ToUpper(sent), data)
+
+		cancel()
+		require.NoError(t, <-done)
+		require.Eventually(t, func() bool { return streams.Load() == 0 }, time.Second, time.Millisecond)
+	}
+}
+
+func TestClient_StreamsLargeMessages(t *testing.T) {
+	t.Parallel()
+
+	_, server := startService(t)
+	client := service.NewClient[string](server.URL, server.Client())
+
+	large := strings.Repeat("x", 256*1024)
+	require.NoError(t, client.Send("in", large))
+
+	for data, err := range client.Stream("out") {
+		require.NoError(t, err)
+		require.Equal(t, "decorated: "+large, data)
+
+		break
+	}
+}
+
+func TestClient_StreamReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, server := startService(t)
+	client := service.NewClient[string](server.URL, server.Client())
+
+	count := 0
+
+	for _, err := range client.Stream("missing") {
+		require.ErrorIs(t, err, conveyer.ErrChanNotFound)
+
+		count++
+	}
+
+	require.Equal(t, 1, count)
+}
+
+func TestClient_MapsFailures(t *testing.T) {
+	t.Parallel()
+
+	for name, test := range map[string]struct {
+		status int
+		sent   int
+		err    error
+	}{
+		"partial batch": {status: http.StatusServiceUnavailable, sent: 2, err: conveyer.ErrShutdown},
+		"timeout":       {status: http.StatusRequestTimeout, sent: 0, err: context.DeadlineExceeded},
+		"reserved":      {status: http.StatusConflict, sent: 0, err: conveyer.ErrReservedForCalls},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(test.status)
+			_ = json.NewEncoder(writer).Encode(map[string]any{"error": "failed", "sent": test.sent})
+		}))
+
+		client := service.NewClient[string](server.URL, server.Client())
+		sent, err := client.SendBatch(context.Background(), "in", []string{"a", "b", "c"})
+
+		server.Close()
+
+		require.ErrorIs(t, err, test.err, name)
+		require.Equal(t, test.sent, sent, name)
+	}
+}
+
+func TestServer_ServerSentEvents(t *testing.T) {
+	t.Parallel()
+
+	pipeline, server := startService(t)
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		server.URL+"/channels/out/stream", nil)
+	require.NoError(t, err)
+	request.Header.Set("Accept", "text/event-stream")
+
+	response, err := server.Client().Do(request)
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	require.NoError(t, pipeline.Send("in", "a"))
+
+	reader := bufio.NewReader(response.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, `data: {"data":"decorated: a"}`, strings.TrimSpace(line))
+
+	_, err = pipeline.Shutdown(context.Background())
+	require.NoError(t, err)
+
+	var rest strings.Builder
+
+	for {
+		line, err := reader.ReadString('\n')
+		rest.WriteString(line)
+
+		if err != nil {
+			break
+		}
+	}
+
+	require.Contains(t, rest.String(), "event: end")
+}
+
+func TestServer_Status(t *testing.T) {
+	t.Parallel()
+
+	_, server := startService(t)
+
+	require.Eventually(t, func() bool {
+		response, err := server.Client().Get(server.URL + "/status")
+		require.NoError(t, err)
+
+		defer response.Body.Close()
+
+		var status struct {
+			Healthy  bool `json:"healthy"`
+			Running  bool `json:"running"`
+			Handlers []struct {
+				Name  string `json:"name"`
+				State string `json:"state"`
+			} `json:"handlers"`
+		}
+
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&status))
+
+		return response.StatusCode == http.StatusOK && status.Healthy && status.Running &&
+			len(status.Handlers) == 1 && status.Handlers[0].State == "running"
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,137 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+var (
+	ErrBucketOverflow  = errors.New("leaky bucket overflow")
+	ErrInvalidRate     = errors.New("rate must be positive")
+	ErrInvalidCapacity = errors.New("bucket capacity must be positive")
+)
+
+func wait(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func TokenBucket[T any](rate float64, burst int) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if rate <= 0 {
+			return ErrInvalidRate
+		}
+
+		if burst <= 0 {
+			return ErrInvalidCapacity
+		}
+
+		tokens := float64(burst)
+		last := time.Now()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case data, ok := <-input:
+				if !ok {
+					return nil
+				}
+
+				now := time.Now()
+				tokens = min(float64(burst), tokens+now.Sub(last).Seconds()*rate)
+				last = now
+
+				if tokens < 1 {
+					if !wait(ctx, time.Duration((1-tokens)/rate*float64(time.Second))) {
+						return nil
+					}
+
+					tokens, last = 1, time.Now()
+				}
+
+				tokens--
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case output <- data:
+				}
+			}
+		}
+	}
+}
+
+func LeakyBucket[T any](interval time.Duration, capacity int) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if interval <= 0 {
+			return ErrInvalidRate
+		}
+
+		if capacity <= 0 {
+			return ErrInvalidCapacity
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		queue := make([]T, 0, capacity)
+
+		for input != nil || len(queue) > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-ticker.C:
+				if len(queue) == 0 {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case output <- queue[0]:
+					queue = queue[1:]
+				}
+
+			case data, ok := <-input:
+				if !ok {
+					input = nil
+
+					continue
+				}
+
+				if len(queue) >= capacity {
+					if !conveyer.Reject(ctx, data, ErrBucketOverflow.Error()) {
+						return ErrBucketOverflow
+					}
+
+					continue
+				}
+
+				queue = append(queue, data)
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package throttle_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/throttle"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 4)
+	output := make(chan string, 4)
+
+	for _, data := range []string{"a", "b", "c", "d"} {
+		input <- data
+	}
+
+	close(input)
+
+	start := time.Now()
+
+	require.NoError(t, throttle.TokenBucket[string](50, 2)(context.Background(), input, output))
+	require.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	require.Len(t, output, 4)
+
+	invalid := make(chan string)
+
+	err := throttle.TokenBucket[string](0, 1)(context.Background(), input, invalid)
+	require.ErrorIs(t, err, throttle.ErrInvalidRate)
+
+	_, ok := <-invalid
+	require.False(t, ok)
+
+	invalid = make(chan string)
+	err = throttle.TokenBucket[string](10, 0)(context.Background(), input, invalid)
+	require.ErrorIs(t, err, throttle.ErrInvalidCapacity)
+
+	_, ok = <-invalid
+	require.False(t, ok)
+}
+
+func TestLeakyBucket(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 2)
+	output := make(chan string, 2)
+	input <- "a"
+	input <- "b"
+
+	close(input)
+
+	start := time.Now()
+
+	require.NoError(t, throttle.LeakyBucket[string](10*time.Millisecond, 2)(context.Background(), input, output))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	var received []string
+
+	for data := range output {
+		received = append(received, data)
+	}
+
+	require.Equal(t, []string{"a", "b"}, received)
+}
+
+func TestLeakyBucket_Overflow(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 4)
+	output := make(chan string, 4)
+
+	for _, data := range []string{"a", "b", "c", "d"} {
+		input <- data
+	}
+
+	close(input)
+
+	err := throttle.LeakyBucket[string](time.Hour, 2)(context.Background(), input, output)
+	require.ErrorIs(t, err, throttle.ErrBucketOverflow)
+
+	_, ok := <-output
+	require.False(t, ok)
+}
+
+func TestLeakyBucket_DeadLetters(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.New(4)
+	pipeline.RegisterDecorator(throttle.LeakyBucket[string](time.Hour, 2), "in", "out")
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+
+	for _, data := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, pipeline.Send("in", data))
+	}
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	for _, expected := range []string{"c", "d"} {
+		letter, err := pipeline.RecvDeadLetter(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, letter.Data)
+		require.Equal(t, throttle.ErrBucketOverflow.Error(), letter.Reason)
+	}
+}
+
+func TestInvalidSettings(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		decorator conveyer.DecoratorFunc[string]
+		err       error
+	}{
+		{decorator: throttle.LeakyBucket[string](0, 1), err: throttle.ErrInvalidRate},
+		{decorator: throttle.LeakyBucket[string](time.Millisecond, 0), err: throttle.ErrInvalidCapacity},
+	} {
+		output := make(chan string)
+
+		require.ErrorIs(t, testCase.decorator(context.Background(), make(chan string), output), testCase.err)
+
+		_, ok := <-output
+		require.False(t, ok)
+	}
+}
@@ -0,0 +1,11 @@
+// Package tracing carries trace context through a conveyer in message envelopes.
+//
+// Decorator, Multiplexer and Separator run the wrapped handler once for their
+// whole life on plain channels, so handlers that keep state between messages,
+// such as samplers, windows and rate limiters, behave as they do untraced. A
+// span starts when the handler takes an envelope's data and ends when it takes
+// the next one. Everything the handler emits or rejects in between is wrapped
+// in that envelope. Handlers that read ahead, like the merging multiplexers, or
+// that emit a batch after several inputs, like windows, therefore attribute
+// their outputs to the most recently consumed message.
+package tracing
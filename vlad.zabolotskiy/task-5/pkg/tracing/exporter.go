@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	spanKindInternal  = 1
+	instrumentation   = "github.com/se1lzor/task-5/pkg/tracing"
+	exportPermissions = 0o644
+)
+
+var ErrExporterClosed = errors.New("exporter is closed")
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type FileExporter struct {
+	service string
+	mutex   sync.Mutex
+	file    *os.File
+	spans   []Span
+}
+
+func NewFileExporter(path string, service string) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, exportPermissions)
+	if err != nil {
+		return nil, fmt.Errorf("opening trace file: %w", err)
+	}
+
+	return &FileExporter{service: service, mutex: sync.Mutex{}, file: file, spans: nil}, nil
+}
+
+func (e *FileExporter) RecordSpan(span Span) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+func (e *FileExporter) Flush() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.flush()
+}
+
+func (e *FileExporter) flush() error {
+	if e.file == nil {
+		return ErrExporterClosed
+	}
+
+	if len(e.spans) == 0 {
+		return nil
+	}
+
+	content, err := json.Marshal(e.traces())
+	if err != nil {
+		return fmt.Errorf("encoding spans: %w", err)
+	}
+
+	if _, err := e.file.Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("writing spans: %w", err)
+	}
+
+	e.spans = nil
+
+	return nil
+}
+
+func (e *FileExporter) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.file == nil {
+		return nil
+	}
+
+	flushErr := e.flush()
+	closeErr := e.file.Close()
+	e.file = nil
+
+	if closeErr != nil {
+		closeErr = fmt.Errorf("closing trace file: %w", closeErr)
+	}
+
+	return errors.Join(flushErr, closeErr)
+}
+
+func (e *FileExporter) traces() otlpTraces {
+	spans := make([]otlpSpan, len(e.spans))
+
+	for index, span := range e.spans {
+		spans[index] = otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        attributes(span.Attributes),
+		}
+	}
+
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{{
+			Key:   "service.name",
+			Value: otlpValue{StringValue: e.service},
+		}}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: instrumentation},
+			Spans: spans,
+		}},
+	}}}
+}
+
+func attributes(values map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make([]otlpAttribute, len(keys))
+
+	for index, key := range keys {
+		result[index] = otlpAttribute{Key: key, Value: otlpValue{StringValue: values[key]}}
+	}
+
+	return result
+}
@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	traceIDSize = 16
+	spanIDSize  = 8
+)
+
+type Envelope[T any] struct {
+	ID      string
+	Created time.Time
+	TraceID string
+	SpanID  string
+	Data    T
+}
+
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+}
+
+type Tracer interface {
+	RecordSpan(span Span)
+}
+
+type Recorder struct {
+	mutex sync.Mutex
+	spans []Span
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{mutex: sync.Mutex{}, spans: nil}
+}
+
+func (r *Recorder) RecordSpan(span Span) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spans = append(r.spans, span)
+}
+
+func (r *Recorder) Spans() []Span {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Span(nil), r.spans...)
+}
+
+func New[T any](tracer Tracer, name string, data T) Envelope[T] {
+	now := time.Now()
+	envelope := Envelope[T]{
+		ID:      newID(traceIDSize),
+		Created: now,
+		TraceID: newID(traceIDSize),
+		SpanID:  newID(spanIDSize),
+		Data:    data,
+	}
+
+	if tracer != nil {
+		tracer.RecordSpan(Span{
+			TraceID:      envelope.TraceID,
+			SpanID:       envelope.SpanID,
+			ParentSpanID: "",
+			Name:         name,
+			Start:        now,
+			End:          now,
+			Attributes:   map[string]string{"message.id": envelope.ID},
+		})
+	}
+
+	return envelope
+}
+
+func newID(size int) string {
+	buffer := make([]byte, size)
+
+	if _, err := rand.Read(buffer); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(buffer)
+}
@@ -0,0 +1,258 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+	"github.com/se1lzor/task-5/pkg/conveyertest"
+	"github.com/se1lzor/task-5/pkg/handlers"
+	"github.com/se1lzor/task-5/pkg/tracing"
+	"github.com/se1lzor/task-5/pkg/window"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing_PropagatesEnvelope(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracing.NewRecorder()
+	pipeline := conveyer.NewTyped[tracing.Envelope[string]](4)
+	pipeline.RegisterDecorator(
+		tracing.Decorator(recorder, "prefix", handlers.PrefixDecoratorFunc), "in", "decorated")
+	pipeline.RegisterSeparator(
+		tracing.Separator(recorder, "broadcast", handlers.BroadcastSeparator[string]()),
+		"decorated", []string{"left", "right"})
+	pipeline.RegisterMultiplexer(
+		tracing.Multiplexer(recorder, "merge", handlers.FairMultiplexer[string]()),
+		[]string{"left", "right"}, "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	sent := tracing.New(recorder, "send", "a")
+	require.NoError(t, pipeline.Send("in", sent))
+
+	for range 2 {
+		received, err := pipeline.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, "decorated: a", received.Data)
+		require.Equal(t, sent.ID, received.ID)
+		require.Equal(t, sent.TraceID, received.TraceID)
+		require.Equal(t, sent.Created, received.Created)
+		require.NotEqual(t, sent.SpanID, received.SpanID)
+	}
+
+	require.NoError(t, harness.Stop())
+
+	parents := make(map[string]tracing.Span)
+
+	for _, span := range recorder.Spans() {
+		require.Equal(t, sent.TraceID, span.TraceID)
+		require.False(t, span.End.Before(span.Start))
+
+		parents[span.SpanID] = span
+	}
+
+	chain := func(span tracing.Span) []string {
+		var names []string
+
+		for {
+			names = append(names, span.Name)
+
+			parent, exists := parents[span.ParentSpanID]
+			if !exists {
+				return names
+			}
+
+			span = parent
+		}
+	}
+
+	merged, outputs := 0, 0
+
+	for _, span := range recorder.Spans() {
+		if span.Name == "merge" {
+			merged++
+
+			require.Equal(t, []string{"merge", "broadcast", "prefix", "send"}, chain(span))
+
+			count, err := strconv.Atoi(span.Attributes["conveyer.outputs"])
+			require.NoError(t, err)
+
+			outputs += count
+		}
+	}
+
+	require.Equal(t, 2, merged)
+	require.Equal(t, 2, outputs)
+}
+
+func TestTracing_AttributesByIdentity(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracing.NewRecorder()
+	keepSecond := 0
+	pipeline := conveyer.NewTyped[tracing.Envelope[string]](4)
+	pipeline.RegisterDecorator(tracing.Decorator(recorder, "filter", handlers.Filter(func(string) bool {
+		keepSecond++
+
+		return keepSecond%2 == 0
+	})), "in", "filtered")
+	pipeline.RegisterDecorator(tracing.Decorator(recorder, "twice", handlers.FlatMap(func(data string) []string {
+		return []string{data, data}
+	})), "filtered", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	sent := make([]tracing.Envelope[string], 4)
+
+	for index := range sent {
+		sent[index] = tracing.New(recorder, "send", "same")
+		require.NoError(t, pipeline.Send("in", sent[index]))
+	}
+
+	for _, expected := range []string{sent[1].ID, sent[1].ID, sent[3].ID, sent[3].ID} {
+		received, err := pipeline.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, expected, received.ID)
+	}
+
+	require.NoError(t, harness.Stop())
+
+	outputs := make(map[string]string)
+
+	for _, span := range recorder.Spans() {
+		if span.Name == "filter" {
+			outputs[span.Attributes["message.id"]] = span.Attributes["conveyer.outputs"]
+		}
+	}
+
+	require.Equal(t, map[string]string{
+		sent[0].ID: "0", sent[1].ID: "1", sent[2].ID: "0", sent[3].ID: "1",
+	}, outputs)
+}
+
+func TestTracing_RejectKeepsEnvelope(t *testing.T) {
+	t.Parallel()
+
+	pipeline := conveyer.NewTyped[tracing.Envelope[string]](4)
+	require.NoError(t, pipeline.SetDeadLetter("dlq"))
+	pipeline.RegisterDecorator(
+		tracing.Decorator(tracing.NewRecorder(), "prefix", handlers.PrefixDecoratorFunc), "in", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	sent := tracing.New(nil, "send", "no decorator")
+	require.NoError(t, pipeline.Send("in", sent))
+
+	letter, err := pipeline.RecvDeadLetter(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, sent.ID, letter.Data.ID)
+	require.Equal(t, sent.TraceID, letter.Data.TraceID)
+	require.Equal(t, "no decorator", letter.Data.Data)
+	require.Equal(t, handlers.ErrCantBeDecorated.Error(), letter.Reason)
+
+	require.NoError(t, harness.Stop())
+}
+
+func TestTracing_KeepsHandlerState(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracing.NewRecorder()
+	pipeline := conveyer.NewTyped[tracing.Envelope[string]](8)
+	pipeline.RegisterDecorator(tracing.Decorator(recorder, "sample", handlers.Sample[string](2)), "in", "sampled")
+	pipeline.RegisterDecorator(tracing.Decorator(recorder, "batch", window.Count(2, window.Join(","))), "sampled", "out")
+
+	harness := conveyertest.New(t, pipeline)
+	harness.Start()
+
+	sent := make([]tracing.Envelope[string], 8)
+
+	for index := range sent {
+		sent[index] = tracing.New(recorder, "send", strconv.Itoa(index))
+		require.NoError(t, pipeline.Send("in", sent[index]))
+	}
+
+	for _, expected := range []struct {
+		envelope tracing.Envelope[string]
+		data     string
+	}{{sent[2], "0,2"}, {sent[6], "4,6"}} {
+		received, err := pipeline.Recv("out")
+		require.NoError(t, err)
+		require.Equal(t, expected.data, received.Data)
+		require.Equal(t, expected.envelope.ID, received.ID)
+		require.Equal(t, expected.envelope.TraceID, received.TraceID)
+	}
+
+	require.NoError(t, harness.Stop())
+
+	sampled := make(map[string]string)
+
+	for _, span := range recorder.Spans() {
+		if span.Name == "sample" {
+			sampled[span.Attributes["message.id"]] = span.Attributes["conveyer.outputs"]
+		}
+	}
+
+	require.Len(t, sampled, len(sent))
+
+	for index, envelope := range sent {
+		require.Equal(t, strconv.Itoa(1-index%2), sampled[envelope.ID], index)
+	}
+}
+
+func TestFileExporter_WritesOTLP(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	exporter, err := tracing.NewFileExporter(path, "conveyer-test")
+	require.NoError(t, err)
+
+	start := time.Unix(10, 5)
+	exporter.RecordSpan(tracing.Span{
+		TraceID:      "0123456789abcdef0123456789abcdef",
+		SpanID:       "0123456789abcdef",
+		ParentSpanID: "",
+		Name:         "prefix",
+		Start:        start,
+		End:          start.Add(time.Second),
+		Attributes:   map[string]string{"message.id": "m1"},
+	})
+	require.NoError(t, exporter.Close())
+	require.ErrorIs(t, exporter.Flush(), tracing.ErrExporterClosed)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var traces struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []map[string]any `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []map[string]any `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+
+	require.NoError(t, json.Unmarshal(content, &traces))
+	require.Len(t, traces.ResourceSpans, 1)
+
+	span := traces.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	require.Equal(t, "0123456789abcdef0123456789abcdef", span["traceId"])
+	require.Equal(t, "prefix", span["name"])
+	require.Equal(t, "10000000005", span["startTimeUnixNano"])
+	require.Equal(t, "11000000005", span["endTimeUnixNano"])
+	require.NotContains(t, span, "parentSpanId")
+	require.Equal(t, map[string]any{"key": "message.id", "value": map[string]any{"stringValue": "m1"}},
+		span["attributes"].([]any)[0])
+}
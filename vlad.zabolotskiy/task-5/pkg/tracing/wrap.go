@@ -0,0 +1,270 @@
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/se1lzor/task-5/internal/chanutil"
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+type plainHandler[T any] func(ctx context.Context, inputs []chan T, outputs []chan T) error
+
+type tracedHandler[T any] struct {
+	tracer  Tracer
+	name    string
+	inputs  int
+	outputs []chan Envelope[T]
+	handle  plainHandler[T]
+	rejects chan rejection[T]
+	current Envelope[T]
+	span    *Span
+	emitted int
+}
+
+type rejection[T any] struct {
+	data     T
+	reason   string
+	rejected chan bool
+}
+
+func newTracedHandler[T any](
+	tracer Tracer,
+	name string,
+	inputs int,
+	outputs []chan Envelope[T],
+	handle plainHandler[T],
+) *tracedHandler[T] {
+	var current Envelope[T]
+
+	return &tracedHandler[T]{
+		tracer:  tracer,
+		name:    name,
+		inputs:  inputs,
+		outputs: outputs,
+		handle:  handle,
+		rejects: make(chan rejection[T]),
+		current: current,
+		span:    nil,
+		emitted: 0,
+	}
+}
+
+func Decorator[T any](
+	tracer Tracer,
+	name string,
+	decorator conveyer.DecoratorFunc[T],
+) conveyer.DecoratorFunc[Envelope[T]] {
+	return func(ctx context.Context, input chan Envelope[T], output chan Envelope[T]) error {
+		defer close(output)
+
+		traced := newTracedHandler(tracer, name, 1, []chan Envelope[T]{output},
+			func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+				return decorator(ctx, inputs[0], outputs[0])
+			})
+
+		return traced.run(ctx, []chan Envelope[T]{input})
+	}
+}
+
+func Multiplexer[T any](
+	tracer Tracer,
+	name string,
+	multiplexer conveyer.MultiplexerFunc[T],
+) conveyer.MultiplexerFunc[Envelope[T]] {
+	return func(ctx context.Context, inputs []chan Envelope[T], output chan Envelope[T]) error {
+		defer close(output)
+
+		traced := newTracedHandler(tracer, name, len(inputs), []chan Envelope[T]{output},
+			func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+				return multiplexer(ctx, inputs, outputs[0])
+			})
+
+		return traced.run(ctx, inputs)
+	}
+}
+
+func Separator[T any](
+	tracer Tracer,
+	name string,
+	separator conveyer.SeparatorFunc[T],
+) conveyer.SeparatorFunc[Envelope[T]] {
+	return func(ctx context.Context, input chan Envelope[T], outputs []chan Envelope[T]) error {
+		defer chanutil.CloseAll(outputs)
+
+		traced := newTracedHandler(tracer, name, 1, outputs,
+			func(ctx context.Context, inputs []chan T, outputs []chan T) error {
+				return separator(ctx, inputs[0], outputs)
+			})
+
+		return traced.run(ctx, []chan Envelope[T]{input})
+	}
+}
+
+func (h *tracedHandler[T]) run(ctx context.Context, inputs []chan Envelope[T]) error {
+	plainInputs := chanutil.Make[T](len(inputs))
+	plainOutputs := chanutil.Make[T](len(h.outputs))
+	result := make(chan error, 1)
+
+	handlerCtx := conveyer.WithRejecter(ctx, func(_ context.Context, data T, reason string) bool {
+		rejected := make(chan bool, 1)
+		h.rejects <- rejection[T]{data: data, reason: reason, rejected: rejected}
+
+		return <-rejected
+	})
+
+	go func() {
+		result <- h.handle(handlerCtx, plainInputs, plainOutputs)
+	}()
+
+	err := h.pump(ctx, inputs, plainInputs, plainOutputs, result)
+	h.endSpan()
+
+	return err
+}
+
+func (h *tracedHandler[T]) pump(
+	ctx context.Context,
+	inputs []chan Envelope[T],
+	plainInputs []chan T,
+	plainOutputs []chan T,
+	result <-chan error,
+) error {
+	var (
+		inputCount  = len(inputs)
+		outputStart = 2 * inputCount
+		resultCase  = outputStart + len(plainOutputs)
+		pending     = make([]Envelope[T], inputCount)
+		closed      = make([]bool, inputCount)
+		cancelled   = false
+	)
+
+	cases := chanutil.RecvCases(inputs)
+	cases = append(cases, make([]reflect.SelectCase, inputCount)...)
+	cases = append(cases, chanutil.RecvCases(plainOutputs)...)
+	cases = append(cases, chanutil.RecvCase(result), chanutil.RecvCase(ctx.Done()), chanutil.RecvCase(h.rejects))
+
+	for index := range inputCount {
+		cases[inputCount+index] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.Value{}, Send: reflect.Value{}}
+	}
+
+	cancel := func() {
+		cancelled = true
+
+		for index := range inputCount {
+			cases[index].Chan, cases[inputCount+index].Chan = reflect.Value{}, reflect.Value{}
+
+			if !closed[index] {
+				closed[index] = true
+				close(plainInputs[index])
+			}
+		}
+
+		cases[resultCase+1].Chan = reflect.Value{}
+	}
+
+	for {
+		chosen, value, ok := reflect.Select(cases)
+
+		switch {
+		case chosen < inputCount && !ok:
+			cases[chosen].Chan = reflect.Value{}
+			closed[chosen] = true
+			close(plainInputs[chosen])
+
+		case chosen < inputCount:
+			pending[chosen], _ = value.Interface().(Envelope[T])
+			cases[chosen].Chan = reflect.Value{}
+			cases[inputCount+chosen] = chanutil.SendCase(plainInputs[chosen], pending[chosen].Data)
+
+		case chosen < outputStart:
+			index := chosen - inputCount
+			cases[chosen].Chan = reflect.Value{}
+			cases[index] = chanutil.RecvCase(inputs[index])
+
+			h.beginSpan(pending[index], index)
+
+		case chosen < resultCase && !ok:
+			cases[chosen].Chan = reflect.Value{}
+
+		case chosen < resultCase:
+			data, _ := value.Interface().(T)
+
+			if !cancelled && !h.forward(ctx, chosen-outputStart, data) {
+				cancel()
+			}
+
+		case chosen == resultCase:
+			err, _ := value.Interface().(error)
+
+			return err
+
+		case chosen == resultCase+2:
+			request, _ := value.Interface().(rejection[T])
+			request.rejected <- conveyer.Reject(ctx, h.rewrap(request.data), request.reason)
+
+		default:
+			cancel()
+		}
+	}
+}
+
+func (h *tracedHandler[T]) forward(ctx context.Context, output int, data T) bool {
+	select {
+	case h.outputs[output] <- h.rewrap(data):
+		h.emitted++
+
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (h *tracedHandler[T]) beginSpan(envelope Envelope[T], input int) {
+	h.endSpan()
+
+	h.span = &Span{
+		TraceID:      envelope.TraceID,
+		SpanID:       newID(spanIDSize),
+		ParentSpanID: envelope.SpanID,
+		Name:         h.name,
+		Start:        time.Now(),
+		End:          time.Time{},
+		Attributes: map[string]string{
+			"message.id":     envelope.ID,
+			"conveyer.input": strconv.Itoa(input),
+		},
+	}
+
+	h.current = Envelope[T]{
+		ID:      envelope.ID,
+		Created: envelope.Created,
+		TraceID: envelope.TraceID,
+		SpanID:  h.span.SpanID,
+		Data:    envelope.Data,
+	}
+}
+
+func (h *tracedHandler[T]) endSpan() {
+	if h.span == nil {
+		return
+	}
+
+	h.span.End = time.Now()
+	h.span.Attributes["conveyer.outputs"] = strconv.Itoa(h.emitted)
+
+	if h.tracer != nil {
+		h.tracer.RecordSpan(*h.span)
+	}
+
+	h.span, h.emitted = nil, 0
+}
+
+func (h *tracedHandler[T]) rewrap(data T) Envelope[T] {
+	envelope := h.current
+	envelope.Data = data
+
+	return envelope
+}
@@ -0,0 +1,288 @@
+package window
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/conveyer"
+)
+
+const flushTimeout = 100 * time.Millisecond
+
+var (
+	ErrInvalidWidth = errors.New("window width must be positive")
+	ErrInvalidSize  = errors.New("window size must be positive")
+)
+
+type Aggregate[T any] func(items []T) T
+
+type Clock interface {
+	Now() time.Time
+	After(duration time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+type options struct {
+	clock Clock
+}
+
+type Option func(options *options)
+
+func WithClock(clock Clock) Option {
+	return func(options *options) {
+		options.clock = clock
+	}
+}
+
+func newOptions(opts []Option) options {
+	result := options{clock: realClock{}}
+
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
+
+func Join(separator string) Aggregate[string] {
+	return func(items []string) string {
+		return strings.Join(items, separator)
+	}
+}
+
+func emit[T any](ctx context.Context, output chan T, aggregate Aggregate[T], items []T) bool {
+	if len(items) == 0 {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case output <- aggregate(items):
+		return true
+	}
+}
+
+func flush[T any](ctx context.Context, output chan T, aggregate Aggregate[T], items []T) {
+	if len(items) == 0 {
+		return
+	}
+
+	data := aggregate(items)
+	timer := time.NewTimer(flushTimeout)
+
+	defer timer.Stop()
+
+	select {
+	case output <- data:
+	case <-timer.C:
+		conveyer.Reject(context.WithoutCancel(ctx), data, "window flush timed out")
+	}
+}
+
+func Count[T any](size int, aggregate Aggregate[T]) conveyer.DecoratorFunc[T] {
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if size <= 0 {
+			return ErrInvalidSize
+		}
+
+		var batch []T
+
+		for {
+			select {
+			case <-ctx.Done():
+				flush(ctx, output, aggregate, batch)
+
+				return nil
+
+			case data, ok := <-input:
+				if !ok {
+					emit(ctx, output, aggregate, batch)
+
+					return nil
+				}
+
+				batch = append(batch, data)
+
+				if len(batch) >= size {
+					if !emit(ctx, output, aggregate, batch) {
+						return nil
+					}
+
+					batch = nil
+				}
+			}
+		}
+	}
+}
+
+func Tumbling[T any](width time.Duration, aggregate Aggregate[T], opts ...Option) conveyer.DecoratorFunc[T] {
+	clock := newOptions(opts).clock
+
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if width <= 0 {
+			return ErrInvalidWidth
+		}
+
+		tick := clock.After(width)
+
+		var batch []T
+
+		for {
+			select {
+			case <-ctx.Done():
+				flush(ctx, output, aggregate, batch)
+
+				return nil
+
+			case <-tick:
+				if !emit(ctx, output, aggregate, batch) {
+					return nil
+				}
+
+				batch = nil
+				tick = clock.After(width)
+
+			case data, ok := <-input:
+				if !ok {
+					emit(ctx, output, aggregate, batch)
+
+					return nil
+				}
+
+				batch = append(batch, data)
+			}
+		}
+	}
+}
+
+type timestamped[T any] struct {
+	data T
+	at   time.Time
+}
+
+func since[T any](items []timestamped[T], start time.Time) []timestamped[T] {
+	for index, item := range items {
+		if !item.at.Before(start) {
+			return items[index:]
+		}
+	}
+
+	return nil
+}
+
+func values[T any](items []timestamped[T]) []T {
+	result := make([]T, len(items))
+
+	for index, item := range items {
+		result[index] = item.data
+	}
+
+	return result
+}
+
+func Sliding[T any](
+	width time.Duration,
+	slide time.Duration,
+	aggregate Aggregate[T],
+	opts ...Option,
+) conveyer.DecoratorFunc[T] {
+	clock := newOptions(opts).clock
+
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if width <= 0 || slide <= 0 {
+			return ErrInvalidWidth
+		}
+
+		tick := clock.After(slide)
+
+		var items []timestamped[T]
+
+		for {
+			select {
+			case <-ctx.Done():
+				flush(ctx, output, aggregate, values(items))
+
+				return nil
+
+			case now := <-tick:
+				items = since(items, now.Add(-width))
+
+				if !emit(ctx, output, aggregate, values(items)) {
+					return nil
+				}
+
+				tick = clock.After(slide)
+
+			case data, ok := <-input:
+				if !ok {
+					emit(ctx, output, aggregate, values(items))
+
+					return nil
+				}
+
+				items = append(items, timestamped[T]{data: data, at: clock.Now()})
+			}
+		}
+	}
+}
+
+func Session[T any](gap time.Duration, aggregate Aggregate[T], opts ...Option) conveyer.DecoratorFunc[T] {
+	clock := newOptions(opts).clock
+
+	return func(ctx context.Context, input chan T, output chan T) error {
+		defer close(output)
+
+		if gap <= 0 {
+			return ErrInvalidWidth
+		}
+
+		var (
+			expired <-chan time.Time
+			session []T
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				flush(ctx, output, aggregate, session)
+
+				return nil
+
+			case <-expired:
+				if !emit(ctx, output, aggregate, session) {
+					return nil
+				}
+
+				session, expired = nil, nil
+
+			case data, ok := <-input:
+				if !ok {
+					emit(ctx, output, aggregate, session)
+
+					return nil
+				}
+
+				session = append(session, data)
+				expired = clock.After(gap)
+			}
+		}
+	}
+}
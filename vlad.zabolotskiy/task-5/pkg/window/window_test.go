@@ -0,0 +1,260 @@
+package window_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/se1lzor/task-5/pkg/window"
+
+	"github.com/stretchr/testify/require"
+)
+
+func collect(output chan string) []string {
+	var received []string
+
+	for data := range output {
+		received = append(received, data)
+	}
+
+	return received
+}
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 5)
+	output := make(chan string, 5)
+
+	for _, data := range []string{"a", "b", "c", "d", "e"} {
+		input <- data
+	}
+
+	close(input)
+
+	require.NoError(t, window.Count(2, window.Join(","))(context.Background(), input, output))
+	require.Equal(t, []string{"a,b", "c,d", "e"}, collect(output))
+}
+
+func TestCount_FlushOnCancel(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 1)
+	output := make(chan string, 1)
+	input <- "a"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- window.Count(10, window.Join(","))(ctx, input, output)
+	}()
+
+	require.Eventually(t, func() bool { return len(input) == 0 }, time.Second, time.Millisecond)
+	cancel()
+
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"a"}, collect(output))
+}
+
+func TestCount_FlushTimesOut(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 1)
+	output := make(chan string)
+	input <- "a"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- window.Count(10, window.Join(","))(ctx, input, output)
+	}()
+
+	require.Eventually(t, func() bool { return len(input) == 0 }, time.Second, time.Millisecond)
+	cancel()
+
+	require.NoError(t, <-done)
+	require.Empty(t, collect(output))
+}
+
+func TestInvalidWidth(t *testing.T) {
+	t.Parallel()
+
+	for name, decorator := range map[string]func(ctx context.Context, input chan string, output chan string) error{
+		"tumbling": window.Tumbling(0, window.Join(",")),
+		"sliding":  window.Sliding(time.Second, -time.Second, window.Join(",")),
+		"session":  window.Session(0, window.Join(",")),
+	} {
+		output := make(chan string)
+
+		require.ErrorIs(t, decorator(context.Background(), make(chan string), output), window.ErrInvalidWidth, name)
+		require.Empty(t, collect(output), name)
+	}
+}
+
+func TestInvalidSize(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{0, -1} {
+		output := make(chan string)
+
+		err := window.Count(size, window.Join(","))(context.Background(), make(chan string), output)
+		require.ErrorIs(t, err, window.ErrInvalidSize)
+		require.Empty(t, collect(output))
+	}
+}
+
+type fakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	calls   chan struct{}
+}
+
+type fakeWaiter struct {
+	at    time.Time
+	fired chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		mutex:   sync.Mutex{},
+		now:     time.Unix(0, 0),
+		waiters: nil,
+		calls:   make(chan struct{}, 16),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.calls <- struct{}{}
+
+	return c.now
+}
+
+func (c *fakeClock) After(duration time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fired := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(duration), fired: fired})
+	c.calls <- struct{}{}
+
+	return fired
+}
+
+func (c *fakeClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(duration)
+	pending := c.waiters[:0]
+
+	for _, waiter := range c.waiters {
+		if waiter.at.After(c.now) {
+			pending = append(pending, waiter)
+
+			continue
+		}
+
+		waiter.fired <- c.now
+	}
+
+	c.waiters = pending
+}
+
+func TestTumbling(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	input := make(chan string)
+	output := make(chan string, 4)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- window.Tumbling(40*time.Millisecond, window.Join(","), window.WithClock(clock))(
+			context.Background(), input, output)
+	}()
+
+	<-clock.calls
+	input <- "a"
+	input <- "b"
+	clock.Advance(40 * time.Millisecond)
+	require.Equal(t, "a,b", <-output)
+
+	input <- "c"
+	close(input)
+
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"c"}, collect(output))
+}
+
+func TestSliding(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	input := make(chan string)
+	output := make(chan string, 8)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- window.Sliding(100*time.Millisecond, 40*time.Millisecond, window.Join(","), window.WithClock(clock))(
+			context.Background(), input, output)
+	}()
+
+	<-clock.calls
+	input <- "a"
+	<-clock.calls
+	clock.Advance(40 * time.Millisecond)
+	require.Equal(t, "a", <-output)
+
+	<-clock.calls
+	input <- "b"
+	<-clock.calls
+	clock.Advance(40 * time.Millisecond)
+	require.Equal(t, "a,b", <-output)
+
+	<-clock.calls
+	clock.Advance(40 * time.Millisecond)
+	require.Equal(t, "b", <-output)
+
+	close(input)
+
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"b"}, collect(output))
+}
+
+func TestSession(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	input := make(chan string)
+	output := make(chan string, 4)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- window.Session(30*time.Millisecond, window.Join(","), window.WithClock(clock))(
+			context.Background(), input, output)
+	}()
+
+	input <- "a"
+	<-clock.calls
+	clock.Advance(20 * time.Millisecond)
+	input <- "b"
+	<-clock.calls
+	clock.Advance(20 * time.Millisecond)
+	input <- "c"
+	<-clock.calls
+	clock.Advance(30 * time.Millisecond)
+	require.Equal(t, "a,b,c", <-output)
+
+	input <- "d"
+	close(input)
+
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"d"}, collect(output))
+}